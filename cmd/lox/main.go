@@ -0,0 +1,43 @@
+// Command lox runs a Lox script, or starts an interactive session when
+// given none.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"mymodule/lox"
+)
+
+func main() {
+	if len(os.Args) > 2 {
+		fmt.Println("Usage: lox [script]")
+		os.Exit(64)
+	} else if len(os.Args) == 2 {
+		runFile(os.Args[1])
+	} else {
+		lox.RunREPL(os.Stdin, os.Stdout, os.Stderr, lox.DefaultHistoryFile())
+	}
+}
+
+func runFile(path string) {
+	err := lox.New(lox.Options{}).RunFile(path)
+	if err == nil {
+		return
+	}
+
+	var pathError *fs.PathError
+	var runtimeError *lox.LoxRuntimeError
+	switch {
+	case errors.As(err, &pathError):
+		fmt.Printf("Error reading file: %v\n", err)
+		os.Exit(1)
+	case errors.As(err, &runtimeError):
+		// Errors have already been reported.
+		os.Exit(70)
+	default:
+		os.Exit(65)
+	}
+}
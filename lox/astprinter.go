@@ -0,0 +1,306 @@
+package lox
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AstPrinter renders syntax trees as S-expressions, one statement per line
+// with nested bodies indented. Given the resolver's locals, variables bound
+// in a local scope are shown as name@depth, where depth is the number of
+// scopes between the use and the declaration; globals are shown bare.
+type AstPrinter struct {
+	locals map[Expr]int
+}
+
+// NewAstPrinter creates an AstPrinter. locals may be nil.
+func NewAstPrinter(locals map[Expr]int) *AstPrinter {
+	return &AstPrinter{locals: locals}
+}
+
+// Print renders a list of statements.
+func (p *AstPrinter) Print(statements []Stmt) string {
+	return strings.Join(p.stmts(statements), "\n")
+}
+
+func (p *AstPrinter) expr(expr Expr) string {
+	return expr.Accept(p).(string)
+}
+
+func (p *AstPrinter) stmt(stmt Stmt) string {
+	return stmt.Accept(p).(string)
+}
+
+func (p *AstPrinter) parenthesize(name string, parts ...string) string {
+	if len(parts) == 0 {
+		return "(" + name + ")"
+	}
+	return "(" + name + " " + strings.Join(parts, " ") + ")"
+}
+
+// block renders a node whose children go on their own indented lines.
+func (p *AstPrinter) block(header string, children ...string) string {
+	var builder strings.Builder
+	builder.WriteString("(" + header)
+	for _, child := range children {
+		builder.WriteString("\n  " + strings.ReplaceAll(child, "\n", "\n  "))
+	}
+	builder.WriteString(")")
+	return builder.String()
+}
+
+func (p *AstPrinter) stmts(statements []Stmt) []string {
+	lines := make([]string, len(statements))
+	for i, stmt := range statements {
+		lines[i] = p.stmt(stmt)
+	}
+	return lines
+}
+
+func (p *AstPrinter) name(expr Expr, name string) string {
+	if depth, found := p.locals[expr]; found {
+		return fmt.Sprintf("%s@%d", name, depth)
+	}
+	return name
+}
+
+func (p *AstPrinter) typed(name string, annotation *TypeAnnotation) string {
+	if annotation == nil {
+		return name
+	}
+	return name + ":" + typeName(annotation)
+}
+
+func typeName(annotation *TypeAnnotation) string {
+	names := make([]string, len(annotation.Names))
+	for i, name := range annotation.Names {
+		names[i] = name.Lexeme
+	}
+	return strings.Join(names, "|")
+}
+
+func (p *AstPrinter) function(header string, params []Token, paramTypes []*TypeAnnotation, returnType *TypeAnnotation, body []Stmt) string {
+	names := make([]string, len(params))
+	for i, param := range params {
+		var annotation *TypeAnnotation
+		if i < len(paramTypes) {
+			annotation = paramTypes[i]
+		}
+		names[i] = p.typed(param.Lexeme, annotation)
+	}
+	header += " (" + strings.Join(names, " ") + ")"
+	if returnType != nil {
+		header += " -> " + typeName(returnType)
+	}
+	return p.block(header, p.stmts(body)...)
+}
+
+// VisitBinaryExpr renders a binary expression.
+func (p *AstPrinter) VisitBinaryExpr(expr *Binary) interface{} {
+	return p.parenthesize(expr.Operator.Lexeme, p.expr(expr.Left), p.expr(expr.Right))
+}
+
+// VisitGroupingExpr renders a parenthesized expression.
+func (p *AstPrinter) VisitGroupingExpr(expr *Grouping) interface{} {
+	return p.parenthesize("group", p.expr(expr.Expression))
+}
+
+// VisitLiteralExpr renders a literal, quoting strings.
+func (p *AstPrinter) VisitLiteralExpr(expr *Literal) interface{} {
+	if text, ok := expr.Value.(string); ok {
+		return fmt.Sprintf("%q", text)
+	}
+	return stringify(expr.Value)
+}
+
+// VisitUnaryExpr renders a unary expression.
+func (p *AstPrinter) VisitUnaryExpr(expr *Unary) interface{} {
+	return p.parenthesize(expr.Operator.Lexeme, p.expr(expr.Right))
+}
+
+// VisitVariableExpr renders a variable reference.
+func (p *AstPrinter) VisitVariableExpr(expr *Variable) interface{} {
+	return p.name(expr, expr.Name.Lexeme)
+}
+
+// VisitAssignExpr renders an assignment.
+func (p *AstPrinter) VisitAssignExpr(expr *Assign) interface{} {
+	return p.parenthesize("=", p.name(expr, expr.Name.Lexeme), p.expr(expr.Value))
+}
+
+// VisitCallExpr renders a call.
+func (p *AstPrinter) VisitCallExpr(expr *Call) interface{} {
+	parts := []string{p.expr(expr.Callee)}
+	for _, argument := range expr.Arguments {
+		parts = append(parts, p.expr(argument))
+	}
+	return p.parenthesize("call", parts...)
+}
+
+// VisitGetExpr renders a property access.
+func (p *AstPrinter) VisitGetExpr(expr *GetExpr) interface{} {
+	return p.parenthesize(".", p.expr(expr.Object), expr.Name.Lexeme)
+}
+
+// VisitSetExpr renders a property assignment.
+func (p *AstPrinter) VisitSetExpr(expr *SetExpr) interface{} {
+	return p.parenthesize(".=", p.expr(expr.Object), expr.Name.Lexeme, p.expr(expr.Value))
+}
+
+// VisitThisExpr renders 'this'.
+func (p *AstPrinter) VisitThisExpr(expr *ThisExpr) interface{} {
+	return p.name(expr, "this")
+}
+
+// VisitSuperExpr renders a superclass method access.
+func (p *AstPrinter) VisitSuperExpr(expr *SuperExpr) interface{} {
+	return p.parenthesize(".", p.name(expr, "super"), expr.Method.Lexeme)
+}
+
+// VisitLogicalExpr renders 'and' and 'or'.
+func (p *AstPrinter) VisitLogicalExpr(expr *Logical) interface{} {
+	return p.parenthesize(expr.Operator.Lexeme, p.expr(expr.Left), p.expr(expr.Right))
+}
+
+// VisitListExpr renders a list literal.
+func (p *AstPrinter) VisitListExpr(expr *ListExpr) interface{} {
+	elements := make([]string, len(expr.Elements))
+	for i, element := range expr.Elements {
+		elements[i] = p.expr(element)
+	}
+	return p.parenthesize("list", elements...)
+}
+
+// VisitIndexExpr renders an index read.
+func (p *AstPrinter) VisitIndexExpr(expr *IndexExpr) interface{} {
+	return p.parenthesize("[]", p.expr(expr.Object), p.expr(expr.Index))
+}
+
+// VisitIndexSetExpr renders an index assignment.
+func (p *AstPrinter) VisitIndexSetExpr(expr *IndexSetExpr) interface{} {
+	return p.parenthesize("[]=", p.expr(expr.Object), p.expr(expr.Index), p.expr(expr.Value))
+}
+
+// VisitMapExpr renders a map literal as its key/value pairs.
+func (p *AstPrinter) VisitMapExpr(expr *MapExpr) interface{} {
+	entries := make([]string, len(expr.Keys))
+	for i := range expr.Keys {
+		entries[i] = p.parenthesize(p.expr(expr.Keys[i]), p.expr(expr.Values[i]))
+	}
+	return p.parenthesize("map", entries...)
+}
+
+// VisitConditionalExpr renders a ternary conditional.
+func (p *AstPrinter) VisitConditionalExpr(expr *Conditional) interface{} {
+	return p.parenthesize("?:", p.expr(expr.Condition), p.expr(expr.ThenBranch), p.expr(expr.ElseBranch))
+}
+
+// VisitLambdaExpr renders an anonymous function.
+func (p *AstPrinter) VisitLambdaExpr(expr *Lambda) interface{} {
+	return p.function("fun", expr.Params, expr.ParamTypes, expr.ReturnType, expr.Body)
+}
+
+// VisitExpressionStmt renders an expression statement.
+func (p *AstPrinter) VisitExpressionStmt(stmt *ExpressionStmt) interface{} {
+	return p.parenthesize("expr", p.expr(stmt.Expression))
+}
+
+// VisitPrintStmt renders a print statement.
+func (p *AstPrinter) VisitPrintStmt(stmt *PrintStmt) interface{} {
+	return p.parenthesize("print", p.expr(stmt.Expression))
+}
+
+// VisitVarStmt renders a variable declaration.
+func (p *AstPrinter) VisitVarStmt(stmt *VarStmt) interface{} {
+	name := p.typed(stmt.Name.Lexeme, stmt.Type)
+	if stmt.Initializer == nil {
+		return p.parenthesize("var", name)
+	}
+	return p.parenthesize("var", name, p.expr(stmt.Initializer))
+}
+
+// VisitBlockStmt renders a block.
+func (p *AstPrinter) VisitBlockStmt(stmt *BlockStmt) interface{} {
+	return p.block("block", p.stmts(stmt.Statements)...)
+}
+
+// VisitIfStmt renders an if statement.
+func (p *AstPrinter) VisitIfStmt(stmt *IfStmt) interface{} {
+	children := []string{p.stmt(stmt.ThenBranch)}
+	if stmt.ElseBranch != nil {
+		children = append(children, p.stmt(stmt.ElseBranch))
+	}
+	return p.block("if "+p.expr(stmt.Condition), children...)
+}
+
+// VisitWhileStmt renders a loop. Desugared for loops show their increment.
+func (p *AstPrinter) VisitWhileStmt(stmt *WhileStmt) interface{} {
+	children := []string{p.stmt(stmt.Body)}
+	if stmt.Increment != nil {
+		children = append(children, p.parenthesize("increment", p.expr(stmt.Increment)))
+	}
+	return p.block("while "+p.expr(stmt.Condition), children...)
+}
+
+// VisitBreakStmt renders a break statement.
+func (p *AstPrinter) VisitBreakStmt(stmt *BreakStmt) interface{} {
+	return p.parenthesize("break")
+}
+
+// VisitContinueStmt renders a continue statement.
+func (p *AstPrinter) VisitContinueStmt(stmt *ContinueStmt) interface{} {
+	return p.parenthesize("continue")
+}
+
+// VisitFunStmt renders a function declaration.
+func (p *AstPrinter) VisitFunStmt(stmt *FunStmt) interface{} {
+	return p.function("fun "+stmt.Name.Lexeme, stmt.Params, stmt.ParamTypes, stmt.ReturnType, stmt.Body)
+}
+
+// VisitReturnStmt renders a return statement.
+func (p *AstPrinter) VisitReturnStmt(stmt *ReturnStmt) interface{} {
+	if stmt.Value == nil {
+		return p.parenthesize("return")
+	}
+	return p.parenthesize("return", p.expr(stmt.Value))
+}
+
+// VisitClassStmt renders a class declaration and its methods.
+func (p *AstPrinter) VisitClassStmt(stmt *ClassStmt) interface{} {
+	header := "class " + stmt.Name.Lexeme
+	if stmt.Superclass != nil {
+		header += " < " + p.expr(stmt.Superclass)
+	}
+	methods := make([]string, len(stmt.Methods))
+	for i, method := range stmt.Methods {
+		methods[i] = p.stmt(method)
+	}
+	return p.block(header, methods...)
+}
+
+// VisitThrowStmt renders a throw statement.
+func (p *AstPrinter) VisitThrowStmt(stmt *ThrowStmt) interface{} {
+	return p.parenthesize("throw", p.expr(stmt.Value))
+}
+
+// VisitTryStmt renders a try statement with its catch and finally clauses.
+func (p *AstPrinter) VisitTryStmt(stmt *TryStmt) interface{} {
+	children := []string{p.block("block", p.stmts(stmt.TryBlock)...)}
+	if stmt.CatchName != nil {
+		children = append(children, p.block("catch "+stmt.CatchName.Lexeme, p.stmts(stmt.CatchBlock)...))
+	}
+	if stmt.FinallyBlock != nil {
+		children = append(children, p.block("finally", p.stmts(stmt.FinallyBlock)...))
+	}
+	return p.block("try", children...)
+}
+
+// VisitImportStmt renders an import statement.
+func (p *AstPrinter) VisitImportStmt(stmt *ImportStmt) interface{} {
+	return p.parenthesize("import", stmt.Path.Lexeme, stmt.Name.Lexeme)
+}
+
+// VisitTypeAliasStmt renders a type alias declaration.
+func (p *AstPrinter) VisitTypeAliasStmt(stmt *TypeAliasStmt) interface{} {
+	return p.parenthesize("type", stmt.Name.Lexeme, typeName(stmt.Type))
+}
@@ -0,0 +1,161 @@
+package lox
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// LoxList is the runtime representation of a list value.
+type LoxList struct {
+	elements []interface{}
+}
+
+// NewLoxList creates a list holding the given elements.
+func NewLoxList(elements []interface{}) *LoxList {
+	return &LoxList{elements: elements}
+}
+
+// Get returns the element at index, reporting errors against token.
+func (l *LoxList) Get(token Token, index interface{}) interface{} {
+	return l.elements[l.checkIndex(token, index)]
+}
+
+// Set replaces the element at index, reporting errors against token.
+func (l *LoxList) Set(token Token, index interface{}, value interface{}) {
+	l.elements[l.checkIndex(token, index)] = value
+}
+
+func (l *LoxList) checkIndex(token Token, index interface{}) int {
+	number, ok := index.(float64)
+	if !ok || number != math.Trunc(number) {
+		panic(runtimeError(token, "List index must be an integer."))
+	}
+	if number < 0 || number >= float64(len(l.elements)) {
+		panic(runtimeError(token, fmt.Sprintf("List index %d out of range for list of length %d.", int(number), len(l.elements))))
+	}
+	return int(number)
+}
+
+func (l *LoxList) String() string {
+	return l.format(stringify)
+}
+
+// format renders the list, rendering each element with element.
+func (l *LoxList) format(element func(interface{}) string) string {
+	parts := make([]string, len(l.elements))
+	for i, value := range l.elements {
+		parts[i] = element(value)
+	}
+	return "[" + strings.Join(parts, ", ") + "]"
+}
+
+// LoxMap is the runtime representation of a map value. Keys are strings,
+// numbers, booleans or nil, and iteration follows insertion order.
+type LoxMap struct {
+	keys   []interface{}
+	values map[interface{}]interface{}
+}
+
+// NewLoxMap creates an empty map.
+func NewLoxMap() *LoxMap {
+	return &LoxMap{values: make(map[interface{}]interface{})}
+}
+
+// Get returns the value stored under key, reporting errors against token.
+func (m *LoxMap) Get(token Token, key interface{}) interface{} {
+	checkMapKey(token, key)
+	value, found := m.values[key]
+	if !found {
+		panic(runtimeError(token, fmt.Sprintf("Key '%s' not found in map.", stringify(key))))
+	}
+	return value
+}
+
+// Set stores value under key, keeping the key's original position if it is
+// already present.
+func (m *LoxMap) Set(token Token, key interface{}, value interface{}) {
+	checkMapKey(token, key)
+	if _, found := m.values[key]; !found {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+// Has reports whether key is present in the map.
+func (m *LoxMap) Has(token Token, key interface{}) bool {
+	checkMapKey(token, key)
+	_, found := m.values[key]
+	return found
+}
+
+// Remove deletes key from the map and returns its value, or nil if it was absent.
+func (m *LoxMap) Remove(token Token, key interface{}) interface{} {
+	checkMapKey(token, key)
+	value, found := m.values[key]
+	if !found {
+		return nil
+	}
+	delete(m.values, key)
+	for i, k := range m.keys {
+		if k == key {
+			m.keys = append(m.keys[:i], m.keys[i+1:]...)
+			break
+		}
+	}
+	return value
+}
+
+// Method returns the built-in map method called name, bound to this map.
+func (m *LoxMap) Method(name Token) interface{} {
+	switch name.Lexeme {
+	case "has":
+		return NewNativeFunction("has", 1, func(arguments []interface{}) (interface{}, error) {
+			return m.Has(name, arguments[0]), nil
+		})
+	case "remove":
+		return NewNativeFunction("remove", 1, func(arguments []interface{}) (interface{}, error) {
+			return m.Remove(name, arguments[0]), nil
+		})
+	case "keys":
+		return NewNativeFunction("keys", 0, func(arguments []interface{}) (interface{}, error) {
+			return NewLoxList(append([]interface{}{}, m.keys...)), nil
+		})
+	case "values":
+		return NewNativeFunction("values", 0, func(arguments []interface{}) (interface{}, error) {
+			values := make([]interface{}, len(m.keys))
+			for i, key := range m.keys {
+				values[i] = m.values[key]
+			}
+			return NewLoxList(values), nil
+		})
+	}
+	hint := didYouMean(name.Lexeme, []string{"has", "remove", "keys", "values"})
+	panic(runtimeError(name, "Undefined map method '"+name.Lexeme+"'."+hint))
+}
+
+func (m *LoxMap) String() string {
+	return m.format(stringify)
+}
+
+// format renders the map, rendering each key and value with element.
+func (m *LoxMap) format(element func(interface{}) string) string {
+	parts := make([]string, len(m.keys))
+	for i, key := range m.keys {
+		parts[i] = element(key) + ": " + element(m.values[key])
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func checkMapKey(token Token, key interface{}) {
+	switch k := key.(type) {
+	case nil, string, bool:
+		return
+	case float64:
+		if math.IsNaN(k) {
+			panic(runtimeError(token, "Map key cannot be NaN."))
+		}
+		return
+	}
+	panic(runtimeError(token, "Map keys must be strings, numbers, booleans or nil."))
+}
@@ -0,0 +1,187 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+)
+
+// toLox converts a Go value passed in by a host program to a Lox value.
+// Booleans, strings and nil are kept; every integer and float type becomes
+// a float64; slices and arrays become lists and maps become maps, with
+// their elements converted in turn. Lox values, such as an instance or a
+// function taken from Global, pass through unchanged.
+func toLox(value interface{}) (interface{}, error) {
+	return newConverter().toLox(value)
+}
+
+// fromLox converts a Lox value to the Go value handed back to a host
+// program: lists become []interface{}, maps become map[string]interface{}
+// when every key is a string and map[interface{}]interface{} otherwise, and
+// everything else is returned as it is.
+func fromLox(value interface{}) interface{} {
+	return newConverter().fromLox(value)
+}
+
+// converter remembers the collections it has converted, so that one
+// reachable twice is converted once and one that contains itself doesn't
+// send it round forever.
+type converter struct {
+	seen map[interface{}]interface{}
+}
+
+// sliceKey identifies a Go slice by where it starts and how long it is.
+type sliceKey struct {
+	data   uintptr
+	length int
+}
+
+func newConverter() *converter {
+	return &converter{seen: make(map[interface{}]interface{})}
+}
+
+func (c *converter) toLox(value interface{}) (interface{}, error) {
+	switch value := value.(type) {
+	case nil, bool, string, float64:
+		return value, nil
+	case *LoxList, *LoxMap, *LoxInstance, *LoxError, *LoxModule, Callable:
+		return value, nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Slice, reflect.Array:
+		return c.listToLox(v)
+	case reflect.Map:
+		return c.mapToLox(v)
+	case reflect.Pointer:
+		if v.IsNil() {
+			return nil, nil
+		}
+	}
+	return nil, fmt.Errorf("Cannot convert %T to a Lox value.", value)
+}
+
+func (c *converter) listToLox(v reflect.Value) (interface{}, error) {
+	if v.Kind() == reflect.Slice {
+		if v.IsNil() {
+			return nil, nil
+		}
+		if list, found := c.seen[sliceKey{v.Pointer(), v.Len()}]; found {
+			return list, nil
+		}
+	}
+
+	list := NewLoxList(make([]interface{}, v.Len()))
+	if v.Kind() == reflect.Slice && v.Len() > 0 {
+		c.seen[sliceKey{v.Pointer(), v.Len()}] = list
+	}
+	for i := range list.elements {
+		element, err := c.toLox(v.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		list.elements[i] = element
+	}
+	return list, nil
+}
+
+func (c *converter) mapToLox(v reflect.Value) (interface{}, error) {
+	if v.IsNil() {
+		return nil, nil
+	}
+	if m, found := c.seen[v.Pointer()]; found {
+		return m, nil
+	}
+
+	m := NewLoxMap()
+	c.seen[v.Pointer()] = m
+	keys := make([]interface{}, 0, v.Len())
+	values := make(map[interface{}]reflect.Value, v.Len())
+	for _, k := range v.MapKeys() {
+		key, err := c.toLox(k.Interface())
+		if err != nil {
+			return nil, err
+		}
+		switch key := key.(type) {
+		case nil, string, bool:
+		case float64:
+			if math.IsNaN(key) {
+				return nil, errors.New("Map key cannot be NaN.")
+			}
+		default:
+			return nil, errors.New("Map keys must be strings, numbers, booleans or nil.")
+		}
+		keys = append(keys, key)
+		values[key] = v.MapIndex(k)
+	}
+
+	// Go maps have no order, so give the Lox map a predictable one.
+	sort.Slice(keys, func(a, b int) bool {
+		return stringify(keys[a]) < stringify(keys[b])
+	})
+	for _, key := range keys {
+		value, err := c.toLox(values[key].Interface())
+		if err != nil {
+			return nil, err
+		}
+		m.keys = append(m.keys, key)
+		m.values[key] = value
+	}
+	return m, nil
+}
+
+func (c *converter) fromLox(value interface{}) interface{} {
+	switch value := value.(type) {
+	case *LoxList:
+		if converted, found := c.seen[value]; found {
+			return converted
+		}
+		elements := make([]interface{}, len(value.elements))
+		c.seen[value] = elements
+		for i, element := range value.elements {
+			elements[i] = c.fromLox(element)
+		}
+		return elements
+	case *LoxMap:
+		if converted, found := c.seen[value]; found {
+			return converted
+		}
+		if allStrings(value.keys) {
+			m := make(map[string]interface{}, len(value.keys))
+			c.seen[value] = m
+			for _, key := range value.keys {
+				m[key.(string)] = c.fromLox(value.values[key])
+			}
+			return m
+		}
+		m := make(map[interface{}]interface{}, len(value.keys))
+		c.seen[value] = m
+		for _, key := range value.keys {
+			m[key] = c.fromLox(value.values[key])
+		}
+		return m
+	}
+	return value
+}
+
+func allStrings(keys []interface{}) bool {
+	for _, key := range keys {
+		if _, ok := key.(string); !ok {
+			return false
+		}
+	}
+	return true
+}
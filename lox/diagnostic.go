@@ -0,0 +1,245 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Diagnostic is an error ready to be shown to the user: what went wrong,
+// where, and any notes such as the calls that led there.
+type Diagnostic struct {
+	Label   string // "error", "type error" or "runtime error"
+	Message string
+	Line    int
+	Column  int
+	Span    Span // the offending source text; empty to mark just Column
+	Notes   []string
+}
+
+// Diagnostic describes the scan error.
+func (e ScanError) Diagnostic() Diagnostic {
+	return Diagnostic{Label: "error", Message: e.Message, Line: e.Line, Column: e.Column, Span: e.Span}
+}
+
+// Diagnostic describes the parse error.
+func (e ParseError) Diagnostic() Diagnostic {
+	return tokenDiagnostic("error", e.Token, e.Message)
+}
+
+// Diagnostic describes the resolve error.
+func (e ResolveError) Diagnostic() Diagnostic {
+	return tokenDiagnostic("error", e.Token, e.Message)
+}
+
+// Diagnostic describes the type error.
+func (e TypeError) Diagnostic() Diagnostic {
+	diagnostic := tokenDiagnostic("type error", e.Token, e.Message)
+	if !e.Span.Empty() {
+		diagnostic.Span = e.Span
+	}
+	return diagnostic
+}
+
+// Diagnostic describes the runtime error, with one note per stack frame.
+func (e *LoxRuntimeError) Diagnostic() Diagnostic {
+	diagnostic := tokenDiagnostic("runtime error", e.Token, e.Message)
+	if !e.Span.Empty() {
+		diagnostic.Span = e.Span
+	}
+	for _, frame := range e.Trace {
+		diagnostic.Notes = append(diagnostic.Notes, fmt.Sprintf("in %s, called at line %d", frame.Function, frame.Line))
+	}
+	return diagnostic
+}
+
+func tokenDiagnostic(label string, token Token, message string) Diagnostic {
+	return Diagnostic{Label: label, Message: message, Line: token.Line, Column: token.Column, Span: token.Span()}
+}
+
+// diagnostics lists the diagnostics carried by an error from one of the
+// interpreter's phases. Other errors yield nothing.
+func diagnostics(err error) []Diagnostic {
+	var result []Diagnostic
+	switch err := err.(type) {
+	case ScanErrors:
+		for _, e := range err {
+			result = append(result, e.Diagnostic())
+		}
+	case ParseErrors:
+		for _, e := range err {
+			result = append(result, e.Diagnostic())
+		}
+	case ResolveErrors:
+		for _, e := range err {
+			result = append(result, e.Diagnostic())
+		}
+	case TypeErrors:
+		for _, e := range err {
+			result = append(result, e.Diagnostic())
+		}
+	case *LoxRuntimeError:
+		result = append(result, err.Diagnostic())
+	case *ModuleError:
+		note := fmt.Sprintf("in module '%s', imported at line %d", err.Module, err.Import.Line)
+		for _, diagnostic := range diagnostics(err.Err) {
+			diagnostic.Notes = append(diagnostic.Notes, note)
+			result = append(result, diagnostic)
+		}
+	}
+	return result
+}
+
+// ANSI escapes used when color is on.
+const (
+	colorReset = "\x1b[0m"
+	colorBold  = "\x1b[1m"
+	colorError = "\x1b[1;31m"
+	colorFrame = "\x1b[1;34m"
+)
+
+// sourceFile is one source text run by an interpreter. Its tokens' offsets
+// start at base.
+type sourceFile struct {
+	name string // shown in diagnostics; empty for REPL input
+	base int
+	text string
+}
+
+// sourceSet keeps every source text an interpreter has run, in the manner of
+// go/token's FileSet: each is given its own range of offsets, so that a span
+// says which text it belongs to. Functions outlive the REPL entry or module
+// that declared them, and their errors must still be shown in context.
+type sourceSet struct {
+	files []sourceFile
+	next  int
+}
+
+func newSourceSet() *sourceSet {
+	// Offset 0 is left unused, so that tokens the parser makes up, which
+	// have no position, are never taken to be in a file.
+	return &sourceSet{next: 1}
+}
+
+// add records a source text and returns the base offset to scan it at.
+func (s *sourceSet) add(name string, text string) int {
+	base := s.next
+	s.files = append(s.files, sourceFile{name: name, base: base, text: text})
+	// Leave a gap so that a span at the very end of a text is unambiguous.
+	s.next += len(text) + 1
+	return base
+}
+
+// file returns the source text offset lies in.
+func (s *sourceSet) file(offset int) (sourceFile, bool) {
+	for i := len(s.files) - 1; i >= 0; i-- {
+		file := s.files[i]
+		if offset >= file.base && offset <= file.base+len(file.text) {
+			return file, true
+		}
+	}
+	return sourceFile{}, false
+}
+
+// diagnosticRenderer prints diagnostics compiler-style, quoting the source
+// line they point at with the offending text underlined:
+//
+//	error: Expect ';' after value.
+//	 --> main.lox:2:7
+//	  |
+//	2 | print 1
+//	  |       ^
+type diagnosticRenderer struct {
+	sources *sourceSet
+	color   bool
+}
+
+// useColor reports whether diagnostics written to w should be colored: w
+// must be a terminal and NO_COLOR unset.
+func useColor(w io.Writer) bool {
+	file, ok := w.(*os.File)
+	return ok && isTerminal(int(file.Fd())) && os.Getenv("NO_COLOR") == ""
+}
+
+func (r diagnosticRenderer) paint(color string, text string) string {
+	if !r.color {
+		return text
+	}
+	return color + text + colorReset
+}
+
+// render writes d to w. The location is taken from the span when its source
+// is known, and from d's line and column otherwise, in which case no snippet
+// is shown.
+func (r diagnosticRenderer) render(w io.Writer, d Diagnostic) {
+	fmt.Fprintf(w, "%s%s\n", r.paint(colorError, d.Label+":"), r.paint(colorBold, " "+d.Message))
+
+	line, column := d.Line, d.Column
+	file, found := r.sources.file(d.Span.Start)
+	var text string
+	var from, to int
+	if found {
+		offset := d.Span.Start - file.base
+		lineStart := strings.LastIndexByte(file.text[:offset], '\n') + 1
+		lineEnd := strings.IndexByte(file.text[lineStart:], '\n')
+		if lineEnd < 0 {
+			lineEnd = len(file.text)
+		} else {
+			lineEnd += lineStart
+		}
+		text = strings.TrimSuffix(file.text[lineStart:lineEnd], "\r")
+		line = strings.Count(file.text[:lineStart], "\n") + 1
+		column = utf8.RuneCountInString(file.text[lineStart:offset]) + 1
+		from, to = offset-lineStart, d.Span.End-file.base-lineStart
+	}
+
+	gutter := strings.Repeat(" ", len(strconv.Itoa(line)))
+	if line > 0 {
+		location := fmt.Sprintf("line %d, column %d", line, column)
+		if file.name != "" {
+			location = fmt.Sprintf("%s:%d:%d", file.name, line, column)
+		}
+		fmt.Fprintf(w, "%s%s %s\n", gutter, r.paint(colorFrame, "-->"), location)
+	}
+
+	if found {
+		bar := r.paint(colorFrame, "|")
+		fmt.Fprintf(w, "%s %s\n", gutter, bar)
+		fmt.Fprintf(w, "%s %s %s\n", r.paint(colorFrame, strconv.Itoa(line)), bar, text)
+		fmt.Fprintf(w, "%s %s %s\n", gutter, bar, r.paint(colorError, underline(text, from, to)))
+	}
+	for _, note := range d.Notes {
+		fmt.Fprintf(w, "%s %s %s\n", gutter, r.paint(colorFrame, "="), note)
+	}
+}
+
+// underline marks text[from:to] with a caret followed by tildes. Spans that
+// run past the end of the line are cut short there, and empty spans get a
+// lone caret. Tabs before the span are kept so the caret lines up.
+func underline(text string, from int, to int) string {
+	if from > len(text) {
+		from = len(text)
+	}
+	if to > len(text) {
+		to = len(text)
+	}
+	if to < from {
+		to = from
+	}
+	var builder strings.Builder
+	for _, char := range text[:from] {
+		if char == '\t' {
+			builder.WriteRune('\t')
+		} else {
+			builder.WriteRune(' ')
+		}
+	}
+	builder.WriteRune('^')
+	if width := utf8.RuneCountInString(text[from:to]); width > 1 {
+		builder.WriteString(strings.Repeat("~", width-1))
+	}
+	return builder.String()
+}
@@ -1,4 +1,4 @@
-package main
+package lox
 
 import "fmt"
 
@@ -25,36 +25,40 @@ func (env *Environment) Define(name string, value interface{}) {
 
 // Get retrieves the value of a variable, checking parent environments if necessary.
 func (env *Environment) Get(name Token) interface{} {
-	// First check the current environment
-	if value, found := env.values[name.Lexeme]; found {
-		return value
-	}
-
-	// Then check parent environments
-	if env.parent != nil {
-		return env.parent.Get(name)
+	for current := env; current != nil; current = current.parent {
+		if value, found := current.values[name.Lexeme]; found {
+			return value
+		}
 	}
 
 	// If not found in any environment, raise an undefined variable error
-	panic(fmt.Sprintf("Undefined variable '%s'.", name.Lexeme))
+	panic(env.undefined(name))
 }
 
 // Assign updates the value of an existing variable, checking parent environments if necessary.
 func (env *Environment) Assign(name Token, value interface{}) {
-	// First check the current environment
-	if _, found := env.values[name.Lexeme]; found {
-		env.values[name.Lexeme] = value
-		return
-	}
-
-	// Then check and update parent environments
-	if env.parent != nil {
-		env.parent.Assign(name, value)
-		return
+	for current := env; current != nil; current = current.parent {
+		if _, found := current.values[name.Lexeme]; found {
+			current.values[name.Lexeme] = value
+			return
+		}
 	}
 
 	// If not found in any environment, raise an undefined variable error
-	panic(fmt.Sprintf("Undefined variable '%s'.", name.Lexeme))
+	panic(env.undefined(name))
+}
+
+// undefined is the error for a variable that isn't bound, suggesting a
+// visible binding that might have been meant.
+func (env *Environment) undefined(name Token) *LoxRuntimeError {
+	var names []string
+	for current := env; current != nil; current = current.parent {
+		for candidate := range current.values {
+			names = append(names, candidate)
+		}
+	}
+	message := fmt.Sprintf("Undefined variable '%s'.", name.Lexeme) + didYouMean(name.Lexeme, names)
+	return runtimeError(name, message)
 }
 
 // Get a variable value at a specific depth.
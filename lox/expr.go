@@ -1,4 +1,4 @@
-package main
+package lox
 
 // Expr is the interface for all expression types.
 type Expr interface {
@@ -18,6 +18,13 @@ type ExprVisitor interface {
 	VisitSetExpr(expr *SetExpr) interface{}
 	VisitThisExpr(expr *ThisExpr) interface{}
 	VisitSuperExpr(expr *SuperExpr) interface{}
+	VisitLogicalExpr(expr *Logical) interface{}
+	VisitListExpr(expr *ListExpr) interface{}
+	VisitIndexExpr(expr *IndexExpr) interface{}
+	VisitIndexSetExpr(expr *IndexSetExpr) interface{}
+	VisitMapExpr(expr *MapExpr) interface{}
+	VisitConditionalExpr(expr *Conditional) interface{}
+	VisitLambdaExpr(expr *Lambda) interface{}
 }
 
 // Binary expression (e.g., a + b).
@@ -33,7 +40,9 @@ func (b *Binary) Accept(visitor ExprVisitor) interface{} {
 
 // Grouping expression (e.g., (expression)).
 type Grouping struct {
+	Paren      Token // the opening '('
 	Expression Expr
+	Closing    Token // the closing ')'
 }
 
 func (g *Grouping) Accept(visitor ExprVisitor) interface{} {
@@ -43,6 +52,7 @@ func (g *Grouping) Accept(visitor ExprVisitor) interface{} {
 // Literal expression (e.g., numbers, strings, nil).
 type Literal struct {
 	Value interface{}
+	Token Token // the literal's token; empty for literals the parser synthesizes
 }
 
 func (l *Literal) Accept(visitor ExprVisitor) interface{} {
@@ -127,4 +137,88 @@ type SuperExpr struct {
 
 func (s *SuperExpr) Accept(visitor ExprVisitor) interface{} {
 	return visitor.VisitSuperExpr(s)
-}
\ No newline at end of file
+}
+
+// Logical represents a short-circuiting logical expression (e.g., a and b).
+type Logical struct {
+	Left     Expr
+	Operator Token
+	Right    Expr
+}
+
+func (l *Logical) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitLogicalExpr(l)
+}
+
+// ListExpr represents a list literal (e.g., [1, 2, 3]).
+type ListExpr struct {
+	Bracket  Token // the opening '['
+	Elements []Expr
+	Closing  Token // the closing ']'
+}
+
+func (l *ListExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitListExpr(l)
+}
+
+// IndexExpr represents an index read (e.g., xs[i]).
+type IndexExpr struct {
+	Object  Expr
+	Bracket Token // the closing ']', used to report errors
+	Index   Expr
+}
+
+func (i *IndexExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitIndexExpr(i)
+}
+
+// IndexSetExpr represents an index assignment (e.g., xs[i] = v).
+type IndexSetExpr struct {
+	Object  Expr
+	Bracket Token
+	Index   Expr
+	Value   Expr
+}
+
+func (i *IndexSetExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitIndexSetExpr(i)
+}
+
+// MapExpr represents a map literal (e.g., {"a": 1, "b": 2}).
+type MapExpr struct {
+	Brace   Token // the opening '{'
+	Keys    []Expr
+	Values  []Expr
+	Closing Token // the closing '}'
+}
+
+func (m *MapExpr) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitMapExpr(m)
+}
+
+// Conditional represents a ternary conditional expression (e.g., a ? b : c).
+type Conditional struct {
+	Condition  Expr
+	ThenBranch Expr
+	ElseBranch Expr
+}
+
+func (c *Conditional) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitConditionalExpr(c)
+}
+
+// Lambda represents an anonymous function expression, either
+// `fun (a) { ... }` or the arrow form `(a) => ...`.
+type Lambda struct {
+	Keyword    Token // the 'fun' keyword or the '=>' arrow
+	Paren      Token // the '(' opening the parameter list
+	Params     []Token
+	ParamTypes []*TypeAnnotation
+	ReturnType *TypeAnnotation
+	Body       []Stmt
+	End        Token // the last token of the body
+}
+
+func (l *Lambda) Accept(visitor ExprVisitor) interface{} {
+	return visitor.VisitLambdaExpr(l)
+}
@@ -0,0 +1,979 @@
+package lox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Interpreter evaluates expressions.
+type Interpreter struct {
+	environment *Environment
+	globals     *Environment
+	builtins    *Environment // natives around the globals, shared with modules
+	locals      map[Expr]int
+	checker     *TypeChecker
+	modules     *moduleLoader
+	sources     *sourceSet    // every source text run, shared with modules
+	file        string        // name of the script being run, for diagnostics
+	dir         string        // directory imports are resolved against
+	stdout      io.Writer     // where print writes
+	stdErr      io.Writer     // where Run reports errors; nil for none
+	stdin       *bufio.Reader // read by input(); nil for none
+	renderer    diagnosticRenderer
+}
+
+// NewInterpreter creates a new instance of the Interpreter. Program output
+// is written to stdout and errors are reported on stdErr; input() reads
+// lines from stdin. Any of them may be nil, to discard output, report
+// nothing, or leave input() with nothing to read.
+func NewInterpreter(stdout io.Writer, stdErr io.Writer, stdin io.Reader) *Interpreter {
+	if stdout == nil {
+		stdout = io.Discard
+	}
+	// The built-ins live in a scope of their own around the globals, so
+	// that a module's namespace holds only what the module defines.
+	builtins := NewEnvironment()
+	globals := NewEnclosedEnvironment(builtins)
+	interpreter := &Interpreter{
+		environment: globals,
+		globals:     globals,
+		builtins:    builtins,
+		locals:      make(map[Expr]int),
+		checker:     NewTypeChecker(),
+		modules:     newModuleLoader(),
+		sources:     newSourceSet(),
+		stdout:      stdout,
+		stdErr:      stdErr,
+	}
+	if reader, buffered := stdin.(*bufio.Reader); buffered {
+		// Used as it is, so a caller reading the same input can share it.
+		interpreter.stdin = reader
+	} else if stdin != nil {
+		interpreter.stdin = bufio.NewReader(stdin)
+	}
+	interpreter.renderer = diagnosticRenderer{sources: interpreter.sources, color: useColor(stdErr)}
+	interpreter.defineStandardLibrary()
+	return interpreter
+}
+
+// Interpret evaluates an expression and prints the result.
+func (i *Interpreter) Interpret(expr Expr) {
+	value, err := i.Evaluate(expr)
+	if err != nil {
+		i.report(err)
+		return
+	}
+	i.println(i.stringify(value))
+}
+
+// println writes a line of program output.
+func (i *Interpreter) println(text string) {
+	fmt.Fprintln(i.stdout, text)
+}
+
+func (i *Interpreter) evaluate(expr Expr) interface{} {
+	return expr.Accept(i)
+}
+
+// VisitLiteralExpr evaluates a literal expression.
+func (i *Interpreter) VisitLiteralExpr(expr *Literal) interface{} {
+	return expr.Value
+}
+
+// VisitGroupingExpr evaluates a grouping expression.
+func (i *Interpreter) VisitGroupingExpr(expr *Grouping) interface{} {
+	return i.evaluate(expr.Expression)
+}
+
+// VisitUnaryExpr evaluates a unary expression.
+func (i *Interpreter) VisitUnaryExpr(expr *Unary) interface{} {
+	right := i.evaluate(expr.Right)
+
+	switch expr.Operator.TokenType {
+	case TokenMinus:
+		checkNumberOperand(expr, right)
+		return -toFloat64(right)
+	case TokenBang:
+		return !isTruthy(right)
+	}
+
+	return nil
+}
+
+// VisitBinaryExpr evaluates a binary expression.
+func (i *Interpreter) VisitBinaryExpr(expr *Binary) interface{} {
+	left := i.evaluate(expr.Left)
+	right := i.evaluate(expr.Right)
+
+	switch expr.Operator.TokenType {
+	case TokenPlus:
+		if isString(left) && isString(right) {
+			return left.(string) + right.(string)
+		} else if isNumber(left) && isNumber(right) {
+			return toFloat64(left) + toFloat64(right)
+		}
+		panic(runtimeError(expr.Operator, "Operands must be two numbers or two strings.").covering(expr))
+
+	case TokenMinus:
+		checkNumberOperands(expr, left, right)
+		return toFloat64(left) - toFloat64(right)
+
+	case TokenStar:
+		checkNumberOperands(expr, left, right)
+		return toFloat64(left) * toFloat64(right)
+
+	case TokenSlash:
+		checkNumberOperands(expr, left, right)
+		if toFloat64(right) == 0 {
+			panic(runtimeError(expr.Operator, "Division by zero.").covering(expr))
+		}
+		return toFloat64(left) / toFloat64(right)
+
+	case TokenGreater:
+		checkNumberOperands(expr, left, right)
+		return toFloat64(left) > toFloat64(right)
+
+	case TokenGreaterEqual:
+		checkNumberOperands(expr, left, right)
+		return toFloat64(left) >= toFloat64(right)
+
+	case TokenLess:
+		checkNumberOperands(expr, left, right)
+		return toFloat64(left) < toFloat64(right)
+
+	case TokenLessEqual:
+		checkNumberOperands(expr, left, right)
+		return toFloat64(left) <= toFloat64(right)
+
+	case TokenEqualEqual:
+		return isEqual(left, right)
+
+	case TokenBangEqual:
+		return !isEqual(left, right)
+	}
+
+	return nil
+}
+
+// VisitLogicalExpr evaluates a logical expression, short-circuiting on the left operand.
+func (i *Interpreter) VisitLogicalExpr(expr *Logical) interface{} {
+	left := i.evaluate(expr.Left)
+
+	if expr.Operator.TokenType == TokenOr {
+		if isTruthy(left) {
+			return left
+		}
+	} else if !isTruthy(left) {
+		return left
+	}
+
+	return i.evaluate(expr.Right)
+}
+
+// VisitListExpr evaluates a list literal into a new list.
+func (i *Interpreter) VisitListExpr(expr *ListExpr) interface{} {
+	elements := make([]interface{}, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		elements = append(elements, i.evaluate(element))
+	}
+	return NewLoxList(elements)
+}
+
+// VisitMapExpr evaluates a map literal into a new map.
+func (i *Interpreter) VisitMapExpr(expr *MapExpr) interface{} {
+	m := NewLoxMap()
+	for index := range expr.Keys {
+		key := i.evaluate(expr.Keys[index])
+		value := i.evaluate(expr.Values[index])
+		m.Set(expr.Brace, key, value)
+	}
+	return m
+}
+
+// VisitIndexExpr reads an element of a list or map.
+func (i *Interpreter) VisitIndexExpr(expr *IndexExpr) interface{} {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+	switch collection := object.(type) {
+	case *LoxList:
+		return collection.Get(expr.Bracket, index)
+	case *LoxMap:
+		return collection.Get(expr.Bracket, index)
+	}
+	panic(runtimeError(expr.Bracket, "Only lists and maps can be indexed."))
+}
+
+// VisitIndexSetExpr assigns to an element of a list or map.
+func (i *Interpreter) VisitIndexSetExpr(expr *IndexSetExpr) interface{} {
+	object := i.evaluate(expr.Object)
+	index := i.evaluate(expr.Index)
+	value := i.evaluate(expr.Value)
+	switch collection := object.(type) {
+	case *LoxList:
+		collection.Set(expr.Bracket, index, value)
+		return value
+	case *LoxMap:
+		collection.Set(expr.Bracket, index, value)
+		return value
+	}
+	panic(runtimeError(expr.Bracket, "Only lists and maps can be indexed."))
+}
+
+// VisitConditionalExpr evaluates only the branch selected by the condition.
+func (i *Interpreter) VisitConditionalExpr(expr *Conditional) interface{} {
+	if isTruthy(i.evaluate(expr.Condition)) {
+		return i.evaluate(expr.ThenBranch)
+	}
+	return i.evaluate(expr.ElseBranch)
+}
+
+// Helper functions
+
+func isTruthy(value interface{}) bool {
+	if value == nil {
+		return false
+	}
+	if b, ok := value.(bool); ok {
+		return b
+	}
+	return true
+}
+
+func isEqual(a, b interface{}) bool {
+	return a == b
+}
+
+// toFloat64 converts an operand that has already been checked to be a number.
+func toFloat64(value interface{}) float64 {
+	return value.(float64)
+}
+
+func checkNumberOperand(expr *Unary, operand interface{}) {
+	if isNumber(operand) {
+		return
+	}
+	panic(runtimeError(expr.Operator, "Operand must be a number.").covering(expr))
+}
+
+func checkNumberOperands(expr *Binary, left, right interface{}) {
+	if isNumber(left) && isNumber(right) {
+		return
+	}
+	panic(runtimeError(expr.Operator, fmt.Sprintf("Operands for %s must be numbers.", expr.Operator.Lexeme)).covering(expr))
+}
+
+func isNumber(value interface{}) bool {
+	_, ok := value.(float64)
+	return ok
+}
+
+func isString(value interface{}) bool {
+	_, ok := value.(string)
+	return ok
+}
+
+// stringify renders a value the way print shows it, without running any
+// Lox code; see Interpreter.stringify for the version that honors toString().
+func stringify(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		return formatNumber(value)
+	case fmt.Stringer:
+		return value.String()
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// formatNumber renders whole numbers without a fraction or exponent, as in
+// 1000000, and other numbers in the shortest form that reads back exactly.
+func formatNumber(number float64) string {
+	switch {
+	case math.IsNaN(number):
+		return "NaN"
+	case math.IsInf(number, 1):
+		return "Infinity"
+	case math.IsInf(number, -1):
+		return "-Infinity"
+	case number == math.Trunc(number) && math.Abs(number) < 1e21:
+		return strconv.FormatFloat(number, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(number, 'g', -1, 64)
+}
+
+// stringify renders a value for print. An instance whose class defines a
+// toString() method is shown as whatever that method returns, including
+// inside lists and maps.
+func (i *Interpreter) stringify(value interface{}) string {
+	switch value := value.(type) {
+	case *LoxInstance:
+		if method := value.class.FindMethod("toString"); method != nil && method.Arity() == 0 {
+			if text, ok := method.Bind(value).Call(i, nil).(string); ok {
+				return text
+			}
+		}
+	case *LoxList:
+		return value.format(i.stringify)
+	case *LoxMap:
+		return value.format(i.stringify)
+	}
+	return stringify(value)
+}
+
+// InterpretStatements executes statements. A runtime error, or a thrown
+// value that nothing caught, stops execution and is returned as a
+// *LoxRuntimeError.
+func (i *Interpreter) InterpretStatements(statements []Stmt) (err error) {
+	defer i.recoverRuntimeError(&err)
+
+	for _, stmt := range statements {
+		if control := i.execute(stmt); control != nil {
+			panic(control.escapeError())
+		}
+	}
+	return nil
+}
+
+// Evaluate evaluates an expression, returning a runtime error rather than
+// panicking as evaluate does.
+func (i *Interpreter) Evaluate(expr Expr) (value interface{}, err error) {
+	defer i.recoverRuntimeError(&err)
+	return i.evaluate(expr), nil
+}
+
+// recoverRuntimeError stores a runtime error, uncaught throw or broken
+// import that is unwinding the interpreter in err. It must be deferred.
+func (i *Interpreter) recoverRuntimeError(err *error) {
+	if r := recover(); r != nil {
+		switch r := r.(type) {
+		case *LoxRuntimeError:
+			*err = r
+		case ThrowValue:
+			*err = r.uncaught()
+		case *ModuleError:
+			*err = r
+		case nativeError:
+			// A native function called directly, not from Lox code.
+			*err = &LoxRuntimeError{Message: r.message}
+		case ReturnValue:
+			i.println(stringify(r.Value))
+		default:
+			panic(r)
+		}
+	}
+}
+
+// execute runs a statement and reports any break or continue that is still
+// looking for its enclosing loop.
+func (i *Interpreter) execute(stmt Stmt) *loopControl {
+	if control, ok := stmt.Accept(i).(*loopControl); ok {
+		return control
+	}
+	return nil
+}
+
+// Statement visitors
+func (i *Interpreter) VisitExpressionStmt(stmt *ExpressionStmt) interface{} {
+	i.evaluate(stmt.Expression)
+	return nil
+}
+
+func (i *Interpreter) VisitPrintStmt(stmt *PrintStmt) interface{} {
+	value := i.evaluate(stmt.Expression)
+	i.println(i.stringify(value))
+	return nil
+}
+
+func (i *Interpreter) VisitVarStmt(stmt *VarStmt) interface{} {
+	var value interface{}
+	if stmt.Initializer != nil {
+		value = i.evaluate(stmt.Initializer)
+	}
+	i.environment.Define(stmt.Name.Lexeme, value)
+	return nil
+}
+
+// VisitVariableExpr retrieves a variable's value from the environment.
+func (i *Interpreter) VisitVariableExpr(expr *Variable) interface{} {
+	return i.lookupVariable(expr.Name, expr)
+}
+
+// VisitAssignExpr assigns a value to a variable in the environment.
+func (i *Interpreter) VisitAssignExpr(expr *Assign) interface{} {
+	value := i.evaluate(expr.Value)
+	if distance, found := i.locals[expr]; found {
+		i.environment.assignAt(distance, expr.Name, value)
+	} else {
+		i.environment.Assign(expr.Name, value)
+	}
+	return value
+}
+
+// Execute a block with its own environment.
+func (i *Interpreter) executeBlock(statements []Stmt, environment *Environment) *loopControl {
+	previous := i.environment
+	defer func() {
+		i.environment = previous
+		if r := recover(); r != nil {
+			if returnValue, ok := r.(ReturnValue); ok {
+				panic(returnValue)
+			}
+			panic(r)
+		}
+	}()
+
+	i.environment = environment
+	for _, stmt := range statements {
+		if control := i.execute(stmt); control != nil {
+			return control
+		}
+	}
+	return nil
+}
+
+// VisitBlockStmt executes a block with a new environment.
+func (i *Interpreter) VisitBlockStmt(stmt *BlockStmt) interface{} {
+	return i.executeBlock(stmt.Statements, NewEnclosedEnvironment(i.environment))
+}
+
+func (i *Interpreter) VisitIfStmt(stmt *IfStmt) interface{} {
+	if isTruthy(i.evaluate(stmt.Condition)) {
+		return i.execute(stmt.ThenBranch)
+	} else if stmt.ElseBranch != nil {
+		return i.execute(stmt.ElseBranch)
+	}
+	return nil
+}
+
+func (i *Interpreter) VisitWhileStmt(stmt *WhileStmt) interface{} {
+	for isTruthy(i.evaluate(stmt.Condition)) {
+		control := i.execute(stmt.Body)
+		if control != nil && control.keyword.TokenType == TokenBreak {
+			break
+		}
+		if stmt.Increment != nil {
+			i.evaluate(stmt.Increment)
+		}
+	}
+	return nil
+}
+
+// loopControl is the completion signal of a break or continue statement. It is
+// returned up through the enclosing statements until the innermost loop
+// consumes it.
+type loopControl struct {
+	keyword Token
+}
+
+// escapeError reports a break or continue that reached a function or script
+// boundary without meeting a loop.
+func (c *loopControl) escapeError() *LoxRuntimeError {
+	return runtimeError(c.keyword, fmt.Sprintf("Cannot use '%s' outside of a loop.", c.keyword.Lexeme))
+}
+
+func (i *Interpreter) VisitBreakStmt(stmt *BreakStmt) interface{} {
+	return &loopControl{keyword: stmt.Keyword}
+}
+
+func (i *Interpreter) VisitContinueStmt(stmt *ContinueStmt) interface{} {
+	return &loopControl{keyword: stmt.Keyword}
+}
+
+// VisitTypeAliasStmt does nothing at runtime; type aliases only matter to the TypeChecker.
+func (i *Interpreter) VisitTypeAliasStmt(stmt *TypeAliasStmt) interface{} {
+	return nil
+}
+
+func (i *Interpreter) VisitThrowStmt(stmt *ThrowStmt) interface{} {
+	panic(ThrowValue{Keyword: stmt.Keyword, Value: i.evaluate(stmt.Value)})
+}
+
+// VisitTryStmt runs the try block, hands anything thrown from it to the catch
+// clause, and always runs the finally clause last. A break or continue in the
+// finally clause discards whatever the try or catch clause was doing.
+func (i *Interpreter) VisitTryStmt(stmt *TryStmt) (result interface{}) {
+	if stmt.FinallyBlock != nil {
+		defer func() {
+			r := recover()
+			if control := i.executeBlock(stmt.FinallyBlock, NewEnclosedEnvironment(i.environment)); control != nil {
+				result = control
+				return
+			}
+			if r != nil {
+				panic(r)
+			}
+		}()
+	}
+	return i.executeTryCatch(stmt)
+}
+
+func (i *Interpreter) executeTryCatch(stmt *TryStmt) (control *loopControl) {
+	if stmt.CatchName != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				value, ok := thrownValue(r)
+				if !ok {
+					panic(r)
+				}
+				environment := NewEnclosedEnvironment(i.environment)
+				environment.Define(stmt.CatchName.Lexeme, value)
+				control = i.executeBlock(stmt.CatchBlock, environment)
+			}
+		}()
+	}
+	return i.executeBlock(stmt.TryBlock, NewEnclosedEnvironment(i.environment))
+}
+
+// ThrowValue carries a value thrown by a Lox throw statement. Like a
+// LoxRuntimeError, it collects a stack trace as it unwinds.
+type ThrowValue struct {
+	Keyword Token
+	Value   interface{}
+	Trace   []StackFrame
+}
+
+func (t ThrowValue) Error() string {
+	return t.uncaught().Error()
+}
+
+// uncaught is the runtime error reported for a value nothing caught.
+func (t ThrowValue) uncaught() *LoxRuntimeError {
+	return &LoxRuntimeError{Token: t.Keyword, Message: "Uncaught exception: " + stringify(t.Value), Trace: t.Trace}
+}
+
+// thrownValue converts a recovered panic into the value a catch clause
+// receives. Only Lox throws and runtime errors are catchable.
+func thrownValue(r interface{}) (interface{}, bool) {
+	switch thrown := r.(type) {
+	case ThrowValue:
+		return thrown.Value, true
+	case *LoxRuntimeError:
+		return &LoxError{message: thrown.Message, line: thrown.Token.Line}, true
+	}
+	return nil, false
+}
+
+// LoxError is the error object a catch clause receives for a runtime error.
+type LoxError struct {
+	message string
+	line    int
+}
+
+// Get returns the error's message or line property.
+func (e *LoxError) Get(name Token) interface{} {
+	switch name.Lexeme {
+	case "message":
+		return e.message
+	case "line":
+		return float64(e.line)
+	}
+	hint := didYouMean(name.Lexeme, []string{"message", "line"})
+	panic(runtimeError(name, "Undefined property '" + name.Lexeme + "'." + hint))
+}
+
+func (e *LoxError) String() string {
+	return e.message
+}
+
+// LoxFunction represents a user-defined function.
+type LoxFunction struct {
+	declaration   *FunStmt
+	closure       *Environment
+	isInitializer bool
+}
+
+func (f *LoxFunction) Call(interpreter *Interpreter, arguments []interface{}) (result interface{}) {
+	// Create a new environment enclosing the closure
+	environment := NewEnclosedEnvironment(f.closure)
+
+	// Bind arguments to parameter names in the new environment
+	for i, param := range f.declaration.Params {
+		environment.Define(param.Lexeme, arguments[i])
+	}
+
+	// Execute the function body; a return statement unwinds to here
+	defer func() {
+		if r := recover(); r != nil {
+			if returnValue, ok := r.(ReturnValue); ok {
+				result = returnValue.Value
+				if f.isInitializer {
+					result = f.closure.getAt(0, "this")
+				}
+				return
+			}
+			panic(r) // Re-panic for other errors
+		}
+	}()
+
+	if control := interpreter.executeBlock(f.declaration.Body, environment); control != nil {
+		panic(control.escapeError())
+	}
+
+	// An initializer always yields the instance it initialized
+	if f.isInitializer {
+		return f.closure.getAt(0, "this")
+	}
+
+	// If no return statement was executed, return nil
+	return nil
+}
+
+func (f *LoxFunction) Arity() int {
+	return len(f.declaration.Params)
+}
+
+func (f *LoxFunction) String() string {
+	if f.declaration.Name.Lexeme == "" {
+		return "<fn anonymous>"
+	}
+	return "<fn " + f.declaration.Name.Lexeme + ">"
+}
+
+func (i *Interpreter) VisitFunStmt(stmt *FunStmt) interface{} {
+	// Create a function that captures the current environment as its closure
+	function := &LoxFunction{
+		declaration: stmt,
+		closure:     i.environment,
+	}
+	i.environment.Define(stmt.Name.Lexeme, function)
+	return nil
+}
+
+// VisitLambdaExpr creates an anonymous function closing over the current environment.
+func (i *Interpreter) VisitLambdaExpr(expr *Lambda) interface{} {
+	return &LoxFunction{
+		declaration: &FunStmt{Params: expr.Params, Body: expr.Body},
+		closure:     i.environment,
+	}
+}
+
+// VisitCallExpr handles function calls
+func (i *Interpreter) VisitCallExpr(expr *Call) interface{} {
+	callee := i.evaluate(expr.Callee)
+
+	var arguments []interface{}
+	for _, arg := range expr.Arguments {
+		arguments = append(arguments, i.evaluate(arg))
+	}
+
+	function, ok := callee.(Callable)
+	if !ok {
+		// Special handling for returned functions
+		if returnedFunc, isFunc := callee.(*LoxFunction); isFunc {
+			function = returnedFunc
+		} else if expr.Paren.TokenType == TokenPipeGreater {
+			panic(runtimeError(expr.Paren, "Can only pipe into functions and classes.").covering(expr))
+		} else {
+			panic(runtimeError(expr.Paren, "Can only call functions and classes.").covering(expr))
+		}
+	}
+
+	if function.Arity() != Variadic && len(arguments) != function.Arity() {
+		panic(runtimeError(expr.Paren, fmt.Sprintf("Expected %d arguments but got %d.", function.Arity(), len(arguments))).covering(expr))
+	}
+
+	// Capture the return value
+	var returnValue interface{}
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				switch r := r.(type) {
+				case ReturnValue:
+					returnValue = r.Value
+					return
+				case nativeError:
+					panic(runtimeError(expr.Paren, r.message).covering(expr))
+				case *LoxRuntimeError:
+					r.Trace = append(r.Trace, callFrame(function, expr.Paren))
+				case ThrowValue:
+					r.Trace = append(r.Trace, callFrame(function, expr.Paren))
+					panic(r)
+				}
+				panic(r)
+			}
+		}()
+		returnValue = function.Call(i, arguments)
+	}()
+
+	return returnValue
+}
+
+// ReturnValue represents a function's return value
+type ReturnValue struct {
+	Value interface{}
+}
+
+func (r ReturnValue) Error() string {
+	return fmt.Sprintf("Return value: %v", r.Value)
+}
+
+// VisitReturnStmt handles return statements
+func (i *Interpreter) VisitReturnStmt(stmt *ReturnStmt) interface{} {
+	var value interface{} = nil
+	if stmt.Value != nil {
+		value = i.evaluate(stmt.Value)
+	}
+	panic(ReturnValue{Value: value})
+}
+
+type Callable interface {
+	Arity() int
+	Call(interpreter *Interpreter, arguments []interface{}) interface{}
+}
+
+// resolve stores the resolution depth of a variable.
+func (i *Interpreter) resolve(expr Expr, depth int) {
+	i.locals[expr] = depth
+}
+
+// Look up a variable's value using its resolution depth.
+func (i *Interpreter) lookupVariable(name Token, expr Expr) interface{} {
+	if distance, found := i.locals[expr]; found {
+		return i.environment.getAt(distance, name.Lexeme)
+	}
+	// If not found in locals, check the global environment.
+	return i.environment.Get(name)
+}
+
+func (i *Interpreter) VisitClassStmt(stmt *ClassStmt) interface{} {
+	var superclass *LoxClass
+	if stmt.Superclass != nil {
+		superValue := i.evaluate(stmt.Superclass)
+		var ok bool
+		superclass, ok = superValue.(*LoxClass)
+		if !ok {
+			panic(runtimeError(stmt.Superclass.Name, "Superclass must be a class."))
+		}
+	}
+
+	i.environment.Define(stmt.Name.Lexeme, nil)
+
+	if stmt.Superclass != nil {
+		i.environment = NewEnclosedEnvironment(i.environment)
+		i.environment.Define("super", superclass)
+	}
+
+	methods := make(map[string]*LoxFunction)
+	for _, method := range stmt.Methods {
+		function := &LoxFunction{
+			declaration:   method,
+			closure:       i.environment,
+			isInitializer: method.Name.Lexeme == "init",
+		}
+		methods[method.Name.Lexeme] = function
+	}
+
+	class := &LoxClass{
+		name:       stmt.Name.Lexeme,
+		superclass: superclass,
+		methods:    methods,
+	}
+
+	if superclass != nil {
+		i.environment = i.environment.parent
+	}
+
+	i.environment.Assign(stmt.Name, class)
+	return nil
+}
+
+func (c *LoxClass) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	instance := &LoxInstance{class: c, fields: make(map[string]interface{})}
+	if initializer := c.findMethod("init"); initializer != nil {
+		initializer.Bind(instance).Call(interpreter, arguments)
+	}
+	return instance
+}
+
+func (c *LoxClass) Arity() int {
+	if initializer := c.findMethod("init"); initializer != nil {
+		return initializer.Arity()
+	}
+	return 0
+}
+
+func (c *LoxClass) findMethod(name string) *LoxFunction {
+	if method, ok := c.methods[name]; ok {
+		return method
+	}
+	if c.superclass != nil {
+		return c.superclass.findMethod(name)
+	}
+	return nil
+}
+
+func (i *Interpreter) VisitGetExpr(expr *GetExpr) interface{} {
+    object := i.evaluate(expr.Object)
+    if instance, ok := object.(*LoxInstance); ok {
+        return instance.Get(expr.Name)
+    }
+    if m, ok := object.(*LoxMap); ok {
+        return m.Method(expr.Name)
+    }
+    if loxError, ok := object.(*LoxError); ok {
+        return loxError.Get(expr.Name)
+    }
+    if module, ok := object.(*LoxModule); ok {
+        return module.Get(expr.Name)
+    }
+    panic(runtimeError(expr.Name, "Only instances have properties."))
+}
+
+func (i *Interpreter) VisitSetExpr(expr *SetExpr) interface{} {
+    object := i.evaluate(expr.Object)
+    if instance, ok := object.(*LoxInstance); ok {
+        value := i.evaluate(expr.Value)
+        instance.Set(expr.Name, value)
+        return value
+    }
+    panic(runtimeError(expr.Name, "Only instances have fields."))
+}
+
+func (i *Interpreter) VisitThisExpr(expr *ThisExpr) interface{} {
+	return i.lookupVariable(expr.Keyword, expr)
+}
+
+func (i *Interpreter) VisitSuperExpr(expr *SuperExpr) interface{} {
+    distance := i.locals[expr]
+    superclass := i.environment.getAt(distance, "super").(*LoxClass)
+    object := i.environment.getAt(distance-1, "this").(*LoxInstance)
+    method := superclass.FindMethod(expr.Method.Lexeme)
+    if method == nil {
+        hint := didYouMean(expr.Method.Lexeme, superclass.methodNames())
+        panic(runtimeError(expr.Method, "Undefined property '" + expr.Method.Lexeme + "'." + hint))
+    }
+    return method.Bind(object)
+}
+
+func (c *LoxClass) FindMethod(name string) *LoxFunction {
+	if method, found := c.methods[name]; found {
+		return method
+	}
+
+	if c.superclass != nil {
+		return c.superclass.FindMethod(name)
+	}
+
+	return nil
+}
+
+func (c *LoxClass) String() string {
+	return c.name
+}
+
+// methodNames lists the methods of the class and its superclasses.
+func (c *LoxClass) methodNames() []string {
+	var names []string
+	for class := c; class != nil; class = class.superclass {
+		for name := range class.methods {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (f *LoxFunction) Bind(instance *LoxInstance) *LoxFunction {
+    environment := NewEnclosedEnvironment(f.closure)
+    environment.Define("this", instance)
+    return &LoxFunction{declaration: f.declaration, closure: environment, isInitializer: f.isInitializer}
+}
+
+// LoxClass represents a runtime class
+type LoxClass struct {
+	name       string
+	superclass *LoxClass
+	methods    map[string]*LoxFunction
+}
+
+// LoxInstance represents an instance of a class
+type LoxInstance struct {
+	class  *LoxClass
+	fields map[string]interface{}
+}
+
+func (i *LoxInstance) Get(name Token) interface{} {
+    if value, ok := i.fields[name.Lexeme]; ok {
+        return value
+    }
+
+    method := i.class.FindMethod(name.Lexeme)
+    if method != nil {
+        return method.Bind(i)
+    }
+
+    names := i.class.methodNames()
+    for field := range i.fields {
+        names = append(names, field)
+    }
+    panic(runtimeError(name, "Undefined property '" + name.Lexeme + "'." + didYouMean(name.Lexeme, names)))
+}
+
+func (i *LoxInstance) String() string {
+	return i.class.name + " instance"
+}
+
+func (i *LoxInstance) Set(name Token, value interface{}) {
+    i.fields[name.Lexeme] = value
+}
+
+// LoxRuntimeError is an error raised while running a Lox program. Token is
+// where it happened and Span, when set, the whole expression at fault; Trace
+// lists the calls that were active, innermost first, and is filled in as the
+// error unwinds through them.
+type LoxRuntimeError struct {
+	Token   Token
+	Span    Span
+	Message string
+	Trace   []StackFrame
+}
+
+// StackFrame is one active call in a LoxRuntimeError's trace.
+type StackFrame struct {
+	Function string // e.g. "area()", "anonymous function" or "module 'math.lox'"
+	Line     int    // line of the call site
+}
+
+func runtimeError(token Token, message string) *LoxRuntimeError {
+	return &LoxRuntimeError{Token: token, Message: message}
+}
+
+// covering marks expr as the expression at fault.
+func (e *LoxRuntimeError) covering(expr Expr) *LoxRuntimeError {
+	e.Span = SpanOf(expr)
+	return e
+}
+
+func (e *LoxRuntimeError) Error() string {
+	var builder strings.Builder
+	builder.WriteString(e.Message)
+	if e.Token.Line > 0 {
+		// Errors raised by a host's direct call have no position.
+		fmt.Fprintf(&builder, "\n[line %d, column %d]", e.Token.Line, e.Token.Column)
+	}
+	for _, frame := range e.Trace {
+		fmt.Fprintf(&builder, "\n  in %s, called at line %d", frame.Function, frame.Line)
+	}
+	return builder.String()
+}
+
+// callFrame describes a call to callee made at paren, for stack traces.
+func callFrame(callee Callable, paren Token) StackFrame {
+	name := "native function"
+	switch callee := callee.(type) {
+	case *LoxFunction:
+		name = "anonymous function"
+		if callee.declaration.Name.Lexeme != "" {
+			name = callee.declaration.Name.Lexeme + "()"
+		}
+	case *LoxClass:
+		name = callee.name + "()"
+	case *NativeFunction:
+		name = callee.name + "()"
+	}
+	return StackFrame{Function: name, Line: paren.Line}
+}
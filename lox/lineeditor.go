@@ -0,0 +1,198 @@
+package lox
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+// errInterrupted is returned by readLine when the user presses Ctrl-C.
+var errInterrupted = errors.New("interrupted")
+
+// lineEditor reads REPL input one line at a time. On a terminal it edits
+// the line in raw mode, with cursor movement and arrow-key recall of
+// earlier lines; on anything else it reads plain newline-terminated lines.
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	fd      int
+	raw     bool
+	history []string
+}
+
+func newLineEditor(in io.Reader, out io.Writer) *lineEditor {
+	editor := &lineEditor{in: bufio.NewReader(in), out: out, fd: -1}
+	if file, ok := in.(*os.File); ok && isTerminal(int(file.Fd())) {
+		editor.fd = int(file.Fd())
+		editor.raw = true
+	}
+	return editor
+}
+
+// addHistory records line so it can be recalled with the arrow keys.
+// Blank lines and immediate repeats are not recorded.
+func (e *lineEditor) addHistory(line string) {
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return
+	}
+	e.history = append(e.history, line)
+}
+
+// readLine shows prompt and returns the next line of input without its line
+// terminator. It returns io.EOF at the end of input and errInterrupted when
+// the line is abandoned with Ctrl-C.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	if e.raw {
+		if restore, err := makeRaw(e.fd); err == nil {
+			defer restore()
+			return e.editLine(prompt)
+		}
+	}
+
+	fmt.Fprint(e.out, prompt)
+	line, err := e.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+func (e *lineEditor) editLine(prompt string) (string, error) {
+	var line []rune
+	cursor := 0
+	// Position in history while browsing with the arrow keys; the line
+	// being typed is kept in draft so coming back down restores it.
+	position := len(e.history)
+	var draft []rune
+
+	refresh := func() {
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(line))
+		if back := len(line) - cursor; back > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dD", back)
+		}
+	}
+	recall := func(to int) {
+		if to < 0 || to > len(e.history) || to == position {
+			return
+		}
+		if position == len(e.history) {
+			draft = line
+		}
+		position = to
+		if position == len(e.history) {
+			line = draft
+		} else {
+			line = []rune(e.history[position])
+		}
+		cursor = len(line)
+		refresh()
+	}
+
+	fmt.Fprint(e.out, prompt)
+	for {
+		key, _, err := e.in.ReadRune()
+		if err != nil {
+			fmt.Fprint(e.out, "\r\n")
+			if len(line) > 0 {
+				return string(line), nil
+			}
+			return "", err
+		}
+
+		switch key {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(line), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if cursor < len(line) {
+				line = append(line[:cursor], line[cursor+1:]...)
+				refresh()
+			}
+		case 127, 8: // Backspace
+			if cursor > 0 {
+				line = append(line[:cursor-1], line[cursor:]...)
+				cursor--
+				refresh()
+			}
+		case 1: // Ctrl-A
+			cursor = 0
+			refresh()
+		case 5: // Ctrl-E
+			cursor = len(line)
+			refresh()
+		case 21: // Ctrl-U
+			line = append([]rune{}, line[cursor:]...)
+			cursor = 0
+			refresh()
+		case 27: // Escape sequence
+			switch e.escapeSequence() {
+			case "[A", "OA":
+				recall(position - 1)
+			case "[B", "OB":
+				recall(position + 1)
+			case "[C", "OC":
+				if cursor < len(line) {
+					cursor++
+					refresh()
+				}
+			case "[D", "OD":
+				if cursor > 0 {
+					cursor--
+					refresh()
+				}
+			case "[H", "OH", "[1~":
+				cursor = 0
+				refresh()
+			case "[F", "OF", "[4~":
+				cursor = len(line)
+				refresh()
+			case "[3~":
+				if cursor < len(line) {
+					line = append(line[:cursor], line[cursor+1:]...)
+					refresh()
+				}
+			}
+		default:
+			if key < ' ' || key == utf8.RuneError {
+				continue
+			}
+			line = append(line[:cursor], append([]rune{key}, line[cursor:]...)...)
+			cursor++
+			refresh()
+		}
+	}
+}
+
+// escapeSequence reads the rest of a CSI or SS3 sequence after ESC and
+// returns it, e.g. "[A" for the up arrow.
+func (e *lineEditor) escapeSequence() string {
+	introducer, _, err := e.in.ReadRune()
+	if err != nil || (introducer != '[' && introducer != 'O') {
+		return ""
+	}
+	sequence := []rune{introducer}
+	for {
+		char, _, err := e.in.ReadRune()
+		if err != nil {
+			return ""
+		}
+		sequence = append(sequence, char)
+		if (char >= 'A' && char <= 'Z') || char == '~' {
+			return string(sequence)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Package lox is a tree-walking interpreter for the Lox language of Crafting
+// Interpreters, extended with lists, maps, modules, exceptions and optional
+// type annotations.
+//
+// A host program creates an interpreter with New and feeds it source:
+//
+//	interpreter := lox.New(lox.Options{Stdout: &output})
+//	interpreter.Define("limit", 10.0)
+//	if err := interpreter.Run(`print limit * 2;`); err != nil {
+//		// err describes what went wrong; it has also been reported on Stderr.
+//	}
+//
+// Definitions persist from one Run to the next, and Call invokes the
+// functions a program defined with Go arguments. Options choose where the
+// program's output, errors and input go; the package never exits.
+package lox
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Value is a Lox value as Go sees it: nil, bool, float64, string, or one of
+// the runtime types such as *LoxList, *LoxMap, *LoxInstance or Callable.
+type Value = interface{}
+
+// Options configures an interpreter made by New.
+type Options struct {
+	// Stdout receives what the program prints. It defaults to os.Stdout.
+	Stdout io.Writer
+	// Stderr receives a diagnostic for every error, quoting the source it
+	// points at. It defaults to os.Stderr.
+	Stderr io.Writer
+	// Stdin supplies the lines input() reads. It defaults to os.Stdin.
+	Stdin io.Reader
+}
+
+// New creates an interpreter with the built-in globals defined. Use
+// io.Discard, or an empty reader, to cut it off from a standard stream.
+func New(opts Options) *Interpreter {
+	stdout, stdErr, stdin := opts.Stdout, opts.Stderr, opts.Stdin
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	if stdErr == nil {
+		stdErr = os.Stderr
+	}
+	if stdin == nil {
+		stdin = os.Stdin
+	}
+	return NewInterpreter(stdout, stdErr, stdin)
+}
+
+// Run runs a program. Nothing runs if a static error is found; the errors
+// are returned as ScanErrors, ParseErrors, ResolveErrors or TypeErrors. A
+// runtime error stops the program and is returned as a *LoxRuntimeError,
+// and a static error in an imported module as a *ModuleError. Either way,
+// the errors are also reported on Stderr.
+func (i *Interpreter) Run(source string) error {
+	return i.run(source, false)
+}
+
+// RunFile runs the script at path. Imports are resolved relative to it and
+// diagnostics name it. An error reading the file is returned as it is.
+func (i *Interpreter) RunFile(path string) error {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	dir, loading, file := i.dir, i.modules.loading, i.file
+	defer func() {
+		i.dir, i.modules.loading, i.file = dir, loading, file
+	}()
+	i.setScriptPath(path)
+	i.file = path
+	return i.run(string(source), false)
+}
+
+// Eval evaluates a single expression, such as "limit * 2", and returns its
+// value. Errors are returned and reported as by Run.
+func (i *Interpreter) Eval(expression string) (Value, error) {
+	tokens, err := i.scan(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := NewParser(tokens, nil)
+	expr, err := parser.Parse()
+	if err == nil && !parser.isAtEnd() {
+		err = parser.error(parser.peek(), "Expect end of expression.")
+	}
+	if parseError, ok := err.(ParseError); ok {
+		return nil, i.report(ParseErrors{parseError})
+	}
+
+	resolver := NewResolver(i)
+	resolver.resolveExpression(expr)
+	if resolveErrors := resolver.Errors(); len(resolveErrors) > 0 {
+		return nil, i.report(ResolveErrors(resolveErrors))
+	}
+
+	value, err := i.Evaluate(expr)
+	if err != nil {
+		return nil, i.report(err)
+	}
+	return value, nil
+}
+
+// Define binds name to value among the built-ins, replacing any existing
+// binding. Like DefineNative, it is seen by the modules the program imports.
+// Go values are converted as for Call; an error is returned, and nothing
+// bound, if value has no Lox equivalent.
+func (i *Interpreter) Define(name string, value Value) error {
+	converted, err := toLox(value)
+	if err != nil {
+		return err
+	}
+	i.builtins.Define(name, converted)
+	return nil
+}
+
+// Global returns the value of the global variable name, converted to Go as
+// Call's results are, and whether it is defined.
+func (i *Interpreter) Global(name string) (Value, bool) {
+	value, found := i.global(name)
+	if !found {
+		return nil, false
+	}
+	return fromLox(value), true
+}
+
+// Call calls the global function or class name with args and returns its
+// result.
+//
+// Arguments are converted to Lox values: every Go integer and float type
+// becomes a number, slices and arrays become lists, and maps become maps.
+// The result is converted back: lists become []interface{}, maps become
+// map[string]interface{} when every key is a string and
+// map[interface{}]interface{} otherwise, and numbers stay float64.
+// Instances, classes and functions are passed through as they are, so a
+// *LoxInstance returned by one call can be handed to CallMethod or to
+// another call.
+//
+// Any error is returned as a *LoxRuntimeError and reported on Stderr, as
+// Run does: a runtime error or uncaught exception in the call, and also an
+// undefined name, a callee that is not callable, an argument with no Lox
+// equivalent or the wrong number of arguments. Errors found before the call
+// have no position.
+func (i *Interpreter) Call(name string, args ...Value) (Value, error) {
+	callee, found := i.global(name)
+	if !found {
+		return nil, i.report(i.globals.undefined(Token{TokenType: TokenIdentifier, Lexeme: name}))
+	}
+	return i.call(name, callee, args)
+}
+
+// global looks name up among the globals and the built-ins around them.
+func (i *Interpreter) global(name string) (interface{}, bool) {
+	for env := i.globals; env != nil; env = env.parent {
+		if value, found := env.values[name]; found {
+			return value, true
+		}
+	}
+	return nil, false
+}
+
+// CallMethod calls the method name on instance, as instance.name(args)
+// would in Lox. Arguments, result and errors are handled as by Call.
+func (i *Interpreter) CallMethod(instance *LoxInstance, name string, args ...Value) (result Value, err error) {
+	var method interface{}
+	func() {
+		defer i.recoverRuntimeError(&err)
+		method = instance.Get(Token{TokenType: TokenIdentifier, Lexeme: name})
+	}()
+	if err != nil {
+		return nil, i.report(err)
+	}
+	return i.call(name, method, args)
+}
+
+// call calls callee, which was found under name, converting args to Lox
+// values and the result back to Go.
+func (i *Interpreter) call(name string, callee interface{}, args []Value) (Value, error) {
+	// Errors found here have no call site in the source to point at.
+	token := Token{TokenType: TokenIdentifier, Lexeme: name}
+	function, ok := callee.(Callable)
+	if !ok {
+		return nil, i.report(runtimeError(token, fmt.Sprintf("'%s' is not a function or class.", name)))
+	}
+
+	arguments := make([]interface{}, len(args))
+	for n, arg := range args {
+		argument, err := toLox(arg)
+		if err != nil {
+			return nil, i.report(runtimeError(token, fmt.Sprintf("Argument %d to '%s': %v", n+1, name, err)))
+		}
+		arguments[n] = argument
+	}
+	if function.Arity() != Variadic && len(arguments) != function.Arity() {
+		return nil, i.report(runtimeError(token, fmt.Sprintf("Expected %d arguments but got %d.", function.Arity(), len(arguments))))
+	}
+
+	var result interface{}
+	err := func() (err error) {
+		defer i.recoverRuntimeError(&err)
+		result = function.Call(i, arguments)
+		return nil
+	}()
+	if err != nil {
+		return nil, i.report(err)
+	}
+	return fromLox(result), nil
+}
+
+// run scans, parses, resolves, type-checks and interprets source, as Run
+// does. When echo is set, the value of every bare expression statement is
+// printed, as the REPL shows it.
+func (i *Interpreter) run(source string, echo bool) error {
+	tokens, err := i.scan(source)
+	if err != nil {
+		return err
+	}
+
+	parser := NewParser(tokens, nil)
+	statements, err := parser.ParseStatements()
+	if err != nil {
+		return i.report(err)
+	}
+
+	resolver := NewResolver(i)
+	resolver.Resolve(statements)
+	if resolveErrors := resolver.Errors(); len(resolveErrors) > 0 {
+		return i.report(ResolveErrors(resolveErrors))
+	}
+
+	if typeErrors := i.checker.Check(statements); len(typeErrors) > 0 {
+		return i.report(TypeErrors(typeErrors))
+	}
+
+	if !echo {
+		err = i.InterpretStatements(statements)
+	} else {
+		for _, stmt := range statements {
+			if err = i.runEcho(stmt); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return i.report(err)
+	}
+	return nil
+}
+
+// report writes err to stdErr, rendering each of its diagnostics with the
+// source it points at, and returns it.
+func (i *Interpreter) report(err error) error {
+	if i.stdErr == nil {
+		return err
+	}
+	for _, diagnostic := range diagnostics(err) {
+		i.renderer.render(i.stdErr, diagnostic)
+	}
+	return err
+}
+
+// scan scans source, recording it so that diagnostics can quote it. Any
+// errors are reported and returned as ScanErrors along with the tokens.
+func (i *Interpreter) scan(source string) ([]Token, error) {
+	scanner := NewScanner(source, nil)
+	scanner.base = i.sources.add(i.file, source)
+	tokens := scanner.ScanTokens()
+	if scanErrors := scanner.Errors(); len(scanErrors) > 0 {
+		return tokens, i.report(scanErrors)
+	}
+	return tokens, nil
+}
+
+// runEcho runs stmt, printing its value if it is an expression statement
+// with a non-nil value.
+func (i *Interpreter) runEcho(stmt Stmt) error {
+	expression, ok := stmt.(*ExpressionStmt)
+	if !ok {
+		return i.InterpretStatements([]Stmt{stmt})
+	}
+	value, err := i.Evaluate(expression.Expression)
+	if err == nil && value != nil {
+		i.println(i.stringify(value))
+	}
+	return err
+}
@@ -0,0 +1,171 @@
+package lox
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// LoxModule is the namespace object an import binds. Its members are the
+// top-level bindings of the imported file.
+type LoxModule struct {
+	name    string
+	path    string
+	globals *Environment
+}
+
+// Get returns the top-level binding called name.
+func (m *LoxModule) Get(name Token) interface{} {
+	if value, found := m.globals.values[name.Lexeme]; found {
+		return value
+	}
+	names := make([]string, 0, len(m.globals.values))
+	for member := range m.globals.values {
+		names = append(names, member)
+	}
+	message := fmt.Sprintf("Module '%s' has no member '%s'.", m.name, name.Lexeme) + didYouMean(name.Lexeme, names)
+	panic(runtimeError(name, message))
+}
+
+func (m *LoxModule) String() string {
+	return "<module " + m.name + ">"
+}
+
+// ModuleError is a static error, such as a syntax error, found in a module
+// when it was imported. Err holds the module's ScanErrors, ParseErrors,
+// ResolveErrors or TypeErrors, positioned in the module's own source, or the
+// ModuleError of a module it imports in turn; Import is the import
+// statement that loaded it.
+type ModuleError struct {
+	Import Token
+	Module string
+	Err    error
+}
+
+func (e *ModuleError) Error() string {
+	return fmt.Sprintf("%v\n[in module '%s', imported at line %d]", e.Err, e.Module, e.Import.Line)
+}
+
+func (e *ModuleError) Unwrap() error {
+	return e.Err
+}
+
+// moduleLoader caches evaluated modules by absolute path and tracks the ones
+// still being evaluated, so that every file runs once and import cycles are
+// reported. One loader is shared by an interpreter and all of its modules.
+type moduleLoader struct {
+	modules map[string]*LoxModule
+	loading []string
+}
+
+func newModuleLoader() *moduleLoader {
+	return &moduleLoader{modules: make(map[string]*LoxModule)}
+}
+
+// setScriptPath records the file the interpreter is running, so imports are
+// resolved relative to it and importing it back is reported as a cycle.
+// RunFile undoes it once the script has run.
+func (i *Interpreter) setScriptPath(path string) {
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+	i.dir = filepath.Dir(path)
+	i.modules.loading = append(i.modules.loading, path)
+}
+
+func (i *Interpreter) VisitImportStmt(stmt *ImportStmt) interface{} {
+	i.environment.Define(stmt.Name.Lexeme, i.importModule(stmt))
+	return nil
+}
+
+// importModule returns the module stmt refers to, evaluating it in its own
+// global environment the first time it is imported.
+func (i *Interpreter) importModule(stmt *ImportStmt) *LoxModule {
+	path := stmt.Path.Literal.(string)
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(i.dir, path)
+	}
+	if absolute, err := filepath.Abs(path); err == nil {
+		path = absolute
+	}
+
+	if module, found := i.modules.modules[path]; found {
+		return module
+	}
+	for index, loading := range i.modules.loading {
+		if loading == path {
+			cycle := append(append([]string{}, i.modules.loading[index:]...), path)
+			for j := range cycle {
+				cycle[j] = filepath.Base(cycle[j])
+			}
+			panic(runtimeError(stmt.Path, "Circular import: "+strings.Join(cycle, " -> ")+"."))
+		}
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		panic(runtimeError(stmt.Path, fmt.Sprintf("Cannot read module '%s'.", stmt.Path.Literal)))
+	}
+
+	// The module gets globals of its own inside the host's built-ins, so it
+	// sees every native the host has defined.
+	moduleInterpreter := NewInterpreter(i.stdout, i.stdErr, nil)
+	moduleInterpreter.builtins = i.builtins
+	moduleInterpreter.globals = NewEnclosedEnvironment(i.builtins)
+	moduleInterpreter.environment = moduleInterpreter.globals
+	moduleInterpreter.locals = i.locals
+	moduleInterpreter.modules = i.modules
+	moduleInterpreter.sources = i.sources
+	moduleInterpreter.stdin = i.stdin // shared, so neither reads ahead of the other
+	moduleInterpreter.dir = filepath.Dir(path)
+
+	statements, err := moduleInterpreter.prepareModule(filepath.Base(path), string(source))
+	if err != nil {
+		panic(&ModuleError{Import: stmt.Keyword, Module: filepath.Base(path), Err: err})
+	}
+
+	i.modules.loading = append(i.modules.loading, path)
+	defer func() {
+		i.modules.loading = i.modules.loading[:len(i.modules.loading)-1]
+	}()
+	if err := moduleInterpreter.InterpretStatements(statements); err != nil {
+		runtimeError, ok := err.(*LoxRuntimeError)
+		if !ok {
+			// A broken module that this one imports.
+			panic(&ModuleError{Import: stmt.Keyword, Module: filepath.Base(path), Err: err})
+		}
+		frame := StackFrame{Function: fmt.Sprintf("module '%s'", filepath.Base(path)), Line: stmt.Keyword.Line}
+		runtimeError.Trace = append(runtimeError.Trace, frame)
+		panic(runtimeError)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	module := &LoxModule{name: name, path: path, globals: moduleInterpreter.globals}
+	i.modules.modules[path] = module
+	return module
+}
+
+// prepareModule scans, parses, resolves and type-checks a module's source.
+func (i *Interpreter) prepareModule(name string, source string) (statements []Stmt, err error) {
+	scanner := NewScanner(source, nil)
+	scanner.base = i.sources.add(name, source)
+	tokens := scanner.ScanTokens()
+	if scanErrors := scanner.Errors(); len(scanErrors) > 0 {
+		return nil, scanErrors
+	}
+
+	statements, err = NewParser(tokens, nil).ParseStatements()
+	if err != nil {
+		return nil, err
+	}
+	resolver := NewResolver(i)
+	resolver.Resolve(statements)
+	if resolveErrors := resolver.Errors(); len(resolveErrors) > 0 {
+		return nil, ResolveErrors(resolveErrors)
+	}
+	if typeErrors := NewTypeChecker().Check(statements); len(typeErrors) > 0 {
+		return nil, TypeErrors(typeErrors)
+	}
+	return statements, nil
+}
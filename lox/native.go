@@ -0,0 +1,151 @@
+package lox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Variadic is the arity of a native function that accepts any number of
+// arguments.
+const Variadic = -1
+
+// NativeFunction is a function implemented in Go and callable from Lox.
+type NativeFunction struct {
+	name  string
+	arity int
+	fn    func(arguments []interface{}) (interface{}, error)
+}
+
+// NewNativeFunction creates a native function. arity may be Variadic, in
+// which case fn checks the arguments itself. An error returned by fn becomes
+// a runtime error at the call.
+func NewNativeFunction(name string, arity int, fn func(arguments []interface{}) (interface{}, error)) *NativeFunction {
+	return &NativeFunction{name: name, arity: arity, fn: fn}
+}
+
+func (n *NativeFunction) Arity() int {
+	return n.arity
+}
+
+func (n *NativeFunction) Call(interpreter *Interpreter, arguments []interface{}) interface{} {
+	result, err := n.fn(arguments)
+	if err != nil {
+		panic(nativeError{message: err.Error()})
+	}
+	return result
+}
+
+func (n *NativeFunction) String() string {
+	return "<native fn>"
+}
+
+// nativeError carries an error from a native function up to the call
+// expression, which reports it as a runtime error there.
+type nativeError struct {
+	message string
+}
+
+// DefineNative makes a Go function available to Lox programs as a global.
+// It is defined among the built-ins, so the modules the program imports see
+// it too.
+func (i *Interpreter) DefineNative(name string, arity int, fn func(arguments []interface{}) (interface{}, error)) {
+	i.builtins.Define(name, NewNativeFunction(name, arity, fn))
+}
+
+// defineStandardLibrary defines the built-in functions every program
+// starts with.
+func (i *Interpreter) defineStandardLibrary() {
+	i.DefineNative("clock", 0, func(arguments []interface{}) (interface{}, error) {
+		return float64(time.Now().UnixNano()) / 1e9, nil
+	})
+	i.DefineNative("type", 1, func(arguments []interface{}) (interface{}, error) {
+		return loxType(arguments[0]), nil
+	})
+	i.DefineNative("str", 1, func(arguments []interface{}) (interface{}, error) {
+		return i.stringify(arguments[0]), nil
+	})
+	i.DefineNative("num", 1, func(arguments []interface{}) (interface{}, error) {
+		switch value := arguments[0].(type) {
+		case float64:
+			return value, nil
+		case string:
+			if number, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				return number, nil
+			}
+		}
+		return nil, fmt.Errorf("Cannot convert %s to a number.", quote(arguments[0]))
+	})
+	i.DefineNative("len", 1, func(arguments []interface{}) (interface{}, error) {
+		switch value := arguments[0].(type) {
+		case string:
+			return float64(utf8.RuneCountInString(value)), nil
+		case *LoxList:
+			return float64(len(value.elements)), nil
+		case *LoxMap:
+			return float64(len(value.keys)), nil
+		}
+		return nil, fmt.Errorf("Cannot take the length of %s.", loxType(arguments[0]))
+	})
+	i.DefineNative("input", Variadic, func(arguments []interface{}) (interface{}, error) {
+		if len(arguments) > 1 {
+			return nil, fmt.Errorf("Expected at most 1 argument but got %d.", len(arguments))
+		}
+		if len(arguments) == 1 {
+			fmt.Fprint(i.stdout, i.stringify(arguments[0]))
+		}
+		return i.readLine(), nil
+	})
+}
+
+// readLine reads a line of input for input(), without its line break. It
+// returns nil once the input is exhausted.
+func (i *Interpreter) readLine() interface{} {
+	if i.stdin == nil {
+		return nil
+	}
+	line, err := i.stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return nil
+	}
+	return strings.TrimRight(line, "\r\n")
+}
+
+// loxType is what type() returns for value.
+func loxType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case *LoxList:
+		return "list"
+	case *LoxMap:
+		return "map"
+	case *LoxClass:
+		return "class"
+	case *LoxInstance:
+		return "instance"
+	case *LoxModule:
+		return "module"
+	case *LoxError:
+		return "error"
+	case Callable:
+		return "function"
+	}
+	return "unknown"
+}
+
+// quote renders a value for an error message, quoting strings.
+func quote(value interface{}) string {
+	if text, ok := value.(string); ok {
+		return "'" + text + "'"
+	}
+	return stringify(value)
+}
@@ -0,0 +1,868 @@
+package lox
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Parser implements a recursive descent parser for Lox
+type Parser struct {
+	tokens  []Token
+	current int
+	stdErr  io.Writer
+	errors  ParseErrors
+}
+
+// NewParser creates a new Parser instance
+func NewParser(tokens []Token, stdErr io.Writer) *Parser {
+	return &Parser{tokens: tokens, stdErr: stdErr}
+}
+
+// Parse starts the parsing process
+func (p *Parser) Parse() (expr Expr, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			if perr, ok := r.(ParseError); ok {
+				err = perr
+			} else {
+				panic(r)
+			}
+		}
+	}()
+
+	expr = p.expression()
+	if len(p.errors) > 0 {
+		return nil, p.errors[0]
+	}
+	return expr, nil
+}
+
+func (p *Parser) expression() Expr {
+	return p.assignment()
+}
+
+func (p *Parser) equality() Expr {
+	expr := p.comparison()
+
+	for p.match(TokenBangEqual, TokenEqualEqual) {
+		operator := p.previous()
+		right := p.comparison()
+		expr = &Binary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+func (p *Parser) comparison() Expr {
+	expr := p.pipe()
+
+	for p.match(TokenGreater, TokenGreaterEqual, TokenLess, TokenLessEqual) {
+		operator := p.previous()
+		right := p.pipe()
+		expr = &Binary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+// Parse a left-associative pipeline. `x |> f(y)` is desugared into the
+// call `f(x, y)` and `x |> f` into `f(x)`.
+func (p *Parser) pipe() Expr {
+	expr := p.term()
+
+	for p.match(TokenPipeGreater) {
+		operator := p.previous()
+		right := p.term()
+		expr = p.pipeCall(operator, expr, right)
+	}
+
+	return expr
+}
+
+func (p *Parser) pipeCall(operator Token, argument Expr, target Expr) Expr {
+	switch callee := target.(type) {
+	case *Call:
+		arguments := append([]Expr{argument}, callee.Arguments...)
+		return &Call{Callee: callee.Callee, Paren: callee.Paren, Arguments: arguments}
+	case *Variable, *GetExpr, *SuperExpr, *IndexExpr, *Grouping, *Lambda:
+		return &Call{Callee: target, Paren: operator, Arguments: []Expr{argument}}
+	}
+
+	panic(p.error(operator, "Expect function or call after '|>'."))
+}
+
+func (p *Parser) term() Expr {
+	expr := p.factor()
+
+	for p.match(TokenMinus, TokenPlus) {
+		operator := p.previous()
+		right := p.factor()
+		expr = &Binary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+func (p *Parser) factor() Expr {
+	expr := p.unary()
+
+	for p.match(TokenSlash, TokenStar) {
+		operator := p.previous()
+		right := p.unary()
+		expr = &Binary{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+func (p *Parser) unary() Expr {
+	if p.match(TokenBang, TokenMinus) {
+		operator := p.previous()
+		right := p.unary()
+		return &Unary{Operator: operator, Right: right}
+	}
+
+	return p.call()
+}
+
+func (p *Parser) primary() Expr {
+	if p.match(TokenThis) {
+		return &ThisExpr{Keyword: p.previous()}
+	}
+	if p.match(TokenSuper) {
+		keyword := p.previous()
+		p.consume(TokenDot, "Expect '.' after 'super'.")
+		method := p.consume(TokenIdentifier, "Expect superclass method name.")
+		return &SuperExpr{Keyword: keyword, Method: method}
+	}
+    if p.match(TokenIdentifier) {
+        return &Variable{Name: p.previous()}
+    }
+    if p.match(TokenFalse) {
+        return &Literal{Value: false, Token: p.previous()}
+    }
+    if p.match(TokenTrue) {
+        return &Literal{Value: true, Token: p.previous()}
+    }
+    if p.match(TokenNil) {
+        return &Literal{Value: nil, Token: p.previous()}
+    }
+    if p.match(TokenNumber, TokenString) {
+        return &Literal{Value: p.previous().Literal, Token: p.previous()}
+    }
+    if p.match(TokenFun) {
+        return p.lambda()
+    }
+    if p.check(TokenLeftParen) && p.isArrowFunction() {
+        return p.arrowFunction()
+    }
+    if p.match(TokenLeftParen) {
+        paren := p.previous()
+        expr := p.expression()
+        closing := p.consume(TokenRightParen, "Expect ')' after expression.")
+        return &Grouping{Paren: paren, Expression: expr, Closing: closing}
+    }
+    if p.match(TokenLeftBracket) {
+        return p.listLiteral()
+    }
+    // A '{' at the start of a statement is always a block; in expression
+    // position it begins a map literal.
+    if p.match(TokenLeftBrace) {
+        return p.mapLiteral()
+    }
+    panic(p.error(p.peek(), "Expect expression."))
+}
+
+func (p *Parser) listLiteral() Expr {
+	bracket := p.previous()
+	var elements []Expr
+	if !p.check(TokenRightBracket) {
+		for {
+			elements = append(elements, p.expression())
+			if !p.match(TokenComma) {
+				break
+			}
+		}
+	}
+	closing := p.consume(TokenRightBracket, "Expect ']' after list elements.")
+	return &ListExpr{Bracket: bracket, Elements: elements, Closing: closing}
+}
+
+func (p *Parser) mapLiteral() Expr {
+	brace := p.previous()
+	var keys, values []Expr
+	if !p.check(TokenRightBrace) {
+		for {
+			keys = append(keys, p.expression())
+			p.consume(TokenColon, "Expect ':' after map key.")
+			values = append(values, p.expression())
+			if !p.match(TokenComma) {
+				break
+			}
+		}
+	}
+	closing := p.consume(TokenRightBrace, "Expect '}' after map entries.")
+	return &MapExpr{Brace: brace, Keys: keys, Values: values, Closing: closing}
+}
+
+func (p *Parser) match(types ...TokenType) bool {
+	for _, t := range types {
+		if p.check(t) {
+			p.advance()
+			return true
+		}
+	}
+	return false
+}
+
+// matchWord consumes the current token if it is the identifier word. Words
+// such as "as" only mean something in one place, so they are not reserved.
+func (p *Parser) matchWord(word string) bool {
+	if p.check(TokenIdentifier) && p.peek().Lexeme == word {
+		p.advance()
+		return true
+	}
+	return false
+}
+
+func (p *Parser) consume(t TokenType, message string) Token {
+	if p.check(t) {
+		return p.advance()
+	}
+
+	panic(p.error(p.peek(), message))
+}
+
+func (p *Parser) check(t TokenType) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	return p.peek().TokenType == t
+}
+
+func (p *Parser) checkNext(t TokenType) bool {
+	if p.isAtEnd() {
+		return false
+	}
+	return p.tokens[p.current+1].TokenType == t
+}
+
+func (p *Parser) advance() Token {
+	if !p.isAtEnd() {
+		p.current++
+	}
+	return p.previous()
+}
+
+func (p *Parser) isAtEnd() bool {
+	return p.peek().TokenType == TokenEof
+}
+
+func (p *Parser) peek() Token {
+	return p.tokens[p.current]
+}
+
+func (p *Parser) previous() Token {
+	return p.tokens[p.current-1]
+}
+
+// error records a parse error at token, reports it on stdErr and returns it
+// so that callers can panic with it to unwind to the enclosing declaration.
+func (p *Parser) error(token Token, message string) ParseError {
+	parseError := ParseError{Token: token, Message: message}
+	p.errors = append(p.errors, parseError)
+	if p.stdErr != nil {
+		_, _ = p.stdErr.Write([]byte(parseError.Error() + "\n"))
+	}
+	return parseError
+}
+
+// synchronize discards tokens until the start of the next statement, so
+// that parsing can resume after an error.
+func (p *Parser) synchronize() {
+	p.advance()
+
+	for !p.isAtEnd() {
+		if p.previous().TokenType == TokenSemicolon {
+			return
+		}
+
+		switch p.peek().TokenType {
+		case TokenClass, TokenFun, TokenVar, TokenFor, TokenIf, TokenWhile, TokenPrint,
+			TokenReturn, TokenBreak, TokenContinue, TokenThrow, TokenTry, TokenImport:
+			return
+		}
+		if p.atTypeAlias() {
+			return
+		}
+
+		p.advance()
+	}
+}
+
+// ParseError represents a parsing error at a token.
+type ParseError struct {
+	Token   Token
+	Message string
+}
+
+func (e ParseError) Error() string {
+	if e.Token.TokenType == TokenEof {
+		return fmt.Sprintf("[line %d] Error at end: %s", e.Token.Line, e.Message)
+	}
+	return fmt.Sprintf("[line %d] Error at '%s': %s", e.Token.Line, e.Token.Lexeme, e.Message)
+}
+
+// ParseErrors is every error found while parsing a program, in source order.
+type ParseErrors []ParseError
+
+func (e ParseErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, parseError := range e {
+		messages[i] = parseError.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// ParseStatements parses a whole program. After a syntax error the parser
+// skips to the next statement and carries on, so that every error in the
+// program is reported; if there were any, they are returned as ParseErrors.
+func (p *Parser) ParseStatements() ([]Stmt, error) {
+	var statements []Stmt
+	for !p.isAtEnd() {
+		if stmt := p.declarationOrRecover(); stmt != nil {
+			statements = append(statements, stmt)
+		}
+	}
+	if len(p.errors) > 0 {
+		return statements, p.errors
+	}
+	return statements, nil
+}
+
+// declarationOrRecover parses a declaration. On a syntax error it
+// synchronizes and returns nil.
+func (p *Parser) declarationOrRecover() (stmt Stmt) {
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(ParseError); !ok {
+				panic(r)
+			}
+			p.synchronize()
+			stmt = nil
+		}
+	}()
+
+	return p.declaration()
+}
+
+func (p *Parser) declaration() Stmt {
+	// A 'fun' not followed by a name starts an anonymous function expression.
+	if p.check(TokenFun) && p.checkNext(TokenIdentifier) {
+		p.advance()
+		return p.function("function")
+	}
+	if p.match(TokenClass) {
+		return p.classDeclaration()
+	}
+	if p.match(TokenVar) {
+		return p.varDeclaration()
+	}
+	if p.match(TokenImport) {
+		return p.importDeclaration()
+	}
+	if p.atTypeAlias() {
+		p.advance()
+		return p.typeAliasDeclaration()
+	}
+	return p.statement()
+}
+
+func (p *Parser) varDeclaration() Stmt {
+	name := p.consume(TokenIdentifier, "Expect variable name.")
+
+	var varType *TypeAnnotation
+	if p.match(TokenColon) {
+		varType = p.typeAnnotation()
+	}
+
+	var initializer Expr
+	if p.match(TokenEqual) {
+		initializer = p.expression()
+	}
+	p.consume(TokenSemicolon, "Expect ';' after variable declaration.")
+	return &VarStmt{Name: name, Type: varType, Initializer: initializer}
+}
+
+// atTypeAlias reports whether the current token starts a type alias. 'type'
+// is not reserved; it starts an alias only when a name follows it.
+func (p *Parser) atTypeAlias() bool {
+	return p.check(TokenIdentifier) && p.peek().Lexeme == "type" && p.checkNext(TokenIdentifier)
+}
+
+func (p *Parser) typeAliasDeclaration() Stmt {
+	name := p.consume(TokenIdentifier, "Expect type alias name.")
+	p.consume(TokenEqual, "Expect '=' after type alias name.")
+	aliased := p.typeAnnotation()
+	p.consume(TokenSemicolon, "Expect ';' after type alias.")
+	return &TypeAliasStmt{Name: name, Type: aliased}
+}
+
+// Parse a type annotation: one or more type names separated by '|'.
+func (p *Parser) typeAnnotation() *TypeAnnotation {
+	annotation := &TypeAnnotation{}
+	for {
+		if !p.match(TokenIdentifier, TokenNil) {
+			panic(p.error(p.peek(), "Expect type name."))
+		}
+		annotation.Names = append(annotation.Names, p.previous())
+		if !p.match(TokenPipe) {
+			break
+		}
+	}
+	return annotation
+}
+
+// Parse an optional ': type' return annotation.
+func (p *Parser) returnType() *TypeAnnotation {
+	if p.match(TokenColon) {
+		return p.typeAnnotation()
+	}
+	return nil
+}
+
+func (p *Parser) importDeclaration() Stmt {
+	keyword := p.previous()
+	path := p.consume(TokenString, "Expect module path string after 'import'.")
+
+	var name Token
+	if p.matchWord("as") {
+		name = p.consume(TokenIdentifier, "Expect module name after 'as'.")
+	} else {
+		base := filepath.Base(path.Literal.(string))
+		base = strings.TrimSuffix(base, filepath.Ext(base))
+		if !isIdentifier(base) {
+			panic(p.error(path, "Expect 'as' and a module name; the file name is not a valid identifier."))
+		}
+		name = Token{TokenType: TokenIdentifier, Lexeme: base, Line: path.Line, Start: path.Start}
+	}
+
+	p.consume(TokenSemicolon, "Expect ';' after import.")
+	return &ImportStmt{Keyword: keyword, Path: path, Name: name}
+}
+
+func (p *Parser) statement() Stmt {
+    if p.match(TokenPrint) {
+        return p.printStatement()
+    }
+    if p.match(TokenReturn) {
+        return p.returnStatement()
+    }
+    if p.match(TokenLeftBrace) {
+        return &BlockStmt{Statements: p.block()}
+    }
+    if p.match(TokenIf) {
+        return p.ifStatement()
+    }
+    if p.match(TokenWhile) {
+        return p.whileStatement()
+    }
+    if p.match(TokenFor) {
+        return p.forStatement()
+    }
+    if p.match(TokenBreak) {
+        return p.breakStatement()
+    }
+    if p.match(TokenContinue) {
+        return p.continueStatement()
+    }
+    if p.match(TokenThrow) {
+        return p.throwStatement()
+    }
+    if p.match(TokenTry) {
+        return p.tryStatement()
+    }
+    return p.expressionStatement()
+}
+
+func (p *Parser) printStatement() Stmt {
+	value := p.expression()
+	p.consume(TokenSemicolon, "Expect ';' after value.")
+	return &PrintStmt{Expression: value}
+}
+
+func (p *Parser) expressionStatement() Stmt {
+	expr := p.expression()
+	p.consume(TokenSemicolon, "Expect ';' after expression.")
+	return &ExpressionStmt{Expression: expr}
+}
+
+// Parse an assignment expression.
+func (p *Parser) assignment() Expr {
+	expr := p.conditional()
+
+	if p.match(TokenEqual) {
+		equals := p.previous()
+		value := p.assignment()
+
+		switch target := expr.(type) {
+		case *Variable:
+			return &Assign{Name: target.Name, Value: value}
+		case *GetExpr:
+			return &SetExpr{Object: target.Object, Name: target.Name, Value: value}
+		case *IndexExpr:
+			return &IndexSetExpr{Object: target.Object, Bracket: target.Bracket, Index: target.Index, Value: value}
+		}
+
+		panic(p.error(equals, "Invalid assignment target."))
+	}
+
+	return expr
+}
+
+// Parse a right-associative conditional expression.
+func (p *Parser) conditional() Expr {
+	expr := p.or()
+
+	if p.match(TokenQuestionMark) {
+		thenBranch := p.expression()
+		p.consume(TokenColon, "Expect ':' after then branch of conditional expression.")
+		elseBranch := p.conditional()
+		expr = &Conditional{Condition: expr, ThenBranch: thenBranch, ElseBranch: elseBranch}
+	}
+
+	return expr
+}
+
+func (p *Parser) or() Expr {
+	expr := p.and()
+
+	for p.match(TokenOr) {
+		operator := p.previous()
+		right := p.and()
+		expr = &Logical{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+func (p *Parser) and() Expr {
+	expr := p.equality()
+
+	for p.match(TokenAnd) {
+		operator := p.previous()
+		right := p.equality()
+		expr = &Logical{Left: expr, Operator: operator, Right: right}
+	}
+
+	return expr
+}
+
+func (p *Parser) block() []Stmt {
+	var statements []Stmt
+
+	for !p.check(TokenRightBrace) && !p.isAtEnd() {
+		statements = append(statements, p.declaration())
+	}
+
+	p.consume(TokenRightBrace, "Expect '}' after block.")
+	return statements
+}
+
+func (p *Parser) ifStatement() Stmt {
+    p.consume(TokenLeftParen, "Expect '(' after 'if'.")
+    condition := p.expression()
+    p.consume(TokenRightParen, "Expect ')' after if condition.")
+
+    thenBranch := p.statement()
+    var elseBranch Stmt
+    if p.match(TokenElse) {
+        elseBranch = p.statement()
+    }
+
+    return &IfStmt{Condition: condition, ThenBranch: thenBranch, ElseBranch: elseBranch}
+}
+
+func (p *Parser) whileStatement() Stmt {
+    p.consume(TokenLeftParen, "Expect '(' after 'while'.")
+    condition := p.expression()
+    p.consume(TokenRightParen, "Expect ')' after condition.")
+    body := p.statement()
+    return &WhileStmt{Condition: condition, Body: body}
+}
+
+func (p *Parser) forStatement() Stmt {
+    p.consume(TokenLeftParen, "Expect '(' after 'for'.")
+    var initializer Stmt
+    if p.match(TokenSemicolon) {
+        initializer = nil
+    } else if p.match(TokenVar) {
+        initializer = p.varDeclaration()
+    } else {
+        initializer = p.expressionStatement()
+    }
+
+    var condition Expr
+    if !p.check(TokenSemicolon) {
+        condition = p.expression()
+    }
+    p.consume(TokenSemicolon, "Expect ';' after loop condition.")
+
+    var increment Expr
+    if !p.check(TokenRightParen) {
+        increment = p.expression()
+    }
+    p.consume(TokenRightParen, "Expect ')' after for clauses.")
+
+    body := p.statement()
+    if condition == nil {
+        condition = &Literal{Value: true}
+    }
+    // The increment stays on the loop rather than in the body so that
+    // continue still runs it.
+    body = &WhileStmt{Condition: condition, Body: body, Increment: increment}
+    if initializer != nil {
+        body = &BlockStmt{Statements: []Stmt{initializer, body}}
+    }
+
+    return body
+}
+
+func (p *Parser) breakStatement() Stmt {
+    keyword := p.previous()
+    p.consume(TokenSemicolon, "Expect ';' after 'break'.")
+    return &BreakStmt{Keyword: keyword}
+}
+
+func (p *Parser) continueStatement() Stmt {
+    keyword := p.previous()
+    p.consume(TokenSemicolon, "Expect ';' after 'continue'.")
+    return &ContinueStmt{Keyword: keyword}
+}
+
+func (p *Parser) throwStatement() Stmt {
+    keyword := p.previous()
+    value := p.expression()
+    p.consume(TokenSemicolon, "Expect ';' after thrown value.")
+    return &ThrowStmt{Keyword: keyword, Value: value}
+}
+
+func (p *Parser) tryStatement() Stmt {
+    keyword := p.previous()
+    p.consume(TokenLeftBrace, "Expect '{' after 'try'.")
+    stmt := &TryStmt{TryBlock: p.block()}
+
+    if p.match(TokenCatch) {
+        p.consume(TokenLeftParen, "Expect '(' after 'catch'.")
+        name := p.consume(TokenIdentifier, "Expect exception variable name.")
+        stmt.CatchName = &name
+        p.consume(TokenRightParen, "Expect ')' after exception variable name.")
+        p.consume(TokenLeftBrace, "Expect '{' before catch body.")
+        stmt.CatchBlock = p.block()
+    }
+
+    if p.match(TokenFinally) {
+        p.consume(TokenLeftBrace, "Expect '{' after 'finally'.")
+        stmt.FinallyBlock = p.block()
+        if stmt.FinallyBlock == nil {
+            stmt.FinallyBlock = []Stmt{}
+        }
+    } else if stmt.CatchName == nil {
+        panic(p.error(keyword, "Expect 'catch' or 'finally' after try block."))
+    }
+
+    return stmt
+}
+
+func (p *Parser) function(kind string) Stmt {
+    name := p.consume(TokenIdentifier, fmt.Sprintf("Expect %s name.", kind))
+    p.consume(TokenLeftParen, fmt.Sprintf("Expect '(' after %s name.", kind))
+    parameters, types := p.parameters()
+    returnType := p.returnType()
+    p.consume(TokenLeftBrace, fmt.Sprintf("Expect '{' before %s body.", kind))
+    body := p.block()
+    return &FunStmt{Name: name, Params: parameters, ParamTypes: types, ReturnType: returnType, Body: body}
+}
+
+// Parse a parameter list up to and including the closing ')', along with
+// each parameter's optional type annotation.
+func (p *Parser) parameters() ([]Token, []*TypeAnnotation) {
+    var parameters []Token
+    var types []*TypeAnnotation
+    if !p.check(TokenRightParen) {
+        for {
+            if len(parameters) >= 255 {
+                p.error(p.peek(), "Cannot have more than 255 parameters.")
+            }
+            parameters = append(parameters, p.consume(TokenIdentifier, "Expect parameter name."))
+            var paramType *TypeAnnotation
+            if p.match(TokenColon) {
+                paramType = p.typeAnnotation()
+            }
+            types = append(types, paramType)
+            if !p.match(TokenComma) {
+                break
+            }
+        }
+    }
+    p.consume(TokenRightParen, "Expect ')' after parameters.")
+    return parameters, types
+}
+
+// Parse an anonymous function after its 'fun' keyword.
+func (p *Parser) lambda() Expr {
+    keyword := p.previous()
+    paren := p.consume(TokenLeftParen, "Expect '(' after 'fun'.")
+    parameters, types := p.parameters()
+    returnType := p.returnType()
+    p.consume(TokenLeftBrace, "Expect '{' before function body.")
+    body := p.block()
+    return &Lambda{Keyword: keyword, Paren: paren, Params: parameters, ParamTypes: types, ReturnType: returnType, Body: body, End: p.previous()}
+}
+
+// isArrowFunction reports whether the '(' at the current token opens the
+// parameter list of an arrow function: identifiers, each with an optional
+// ': type', separated by commas, then ')', an optional ': type' and '=>'. It
+// only looks at the tokens such a list could hold, so a parenthesized
+// expression is turned down at its first or second token rather than scanned
+// to its closing ')'.
+func (p *Parser) isArrowFunction() bool {
+    i := p.current + 1
+    if p.tokens[i].TokenType != TokenRightParen {
+        for {
+            if p.tokens[i].TokenType != TokenIdentifier {
+                return false
+            }
+            i++
+            if p.tokens[i].TokenType == TokenColon {
+                if i = p.skipTypeAnnotation(i + 1); i < 0 {
+                    return false
+                }
+            }
+            if p.tokens[i].TokenType != TokenComma {
+                break
+            }
+            i++
+        }
+        if p.tokens[i].TokenType != TokenRightParen {
+            return false
+        }
+    }
+    i++
+    if p.tokens[i].TokenType == TokenColon {
+        if i = p.skipTypeAnnotation(i + 1); i < 0 {
+            return false
+        }
+    }
+    return p.tokens[i].TokenType == TokenArrow
+}
+
+// skipTypeAnnotation returns the index just past the type annotation starting
+// at token i, or -1 if none starts there.
+func (p *Parser) skipTypeAnnotation(i int) int {
+    for {
+        if t := p.tokens[i].TokenType; t != TokenIdentifier && t != TokenNil {
+            return -1
+        }
+        i++
+        if p.tokens[i].TokenType != TokenPipe {
+            return i
+        }
+        i++
+    }
+}
+
+// Parse an arrow function. An expression body is shorthand for a block
+// that returns it.
+func (p *Parser) arrowFunction() Expr {
+    paren := p.consume(TokenLeftParen, "Expect '(' before parameters.")
+    parameters, types := p.parameters()
+    returnType := p.returnType()
+    arrow := p.consume(TokenArrow, "Expect '=>' after parameters.")
+    if p.match(TokenLeftBrace) {
+        body := p.block()
+        return &Lambda{Keyword: arrow, Paren: paren, Params: parameters, ParamTypes: types, ReturnType: returnType, Body: body, End: p.previous()}
+    }
+    value := p.expression()
+    body := []Stmt{&ReturnStmt{Keyword: arrow, Value: value}}
+    return &Lambda{Keyword: arrow, Paren: paren, Params: parameters, ParamTypes: types, ReturnType: returnType, Body: body, End: p.previous()}
+}
+
+
+func (p *Parser) call() Expr {
+    expr := p.primary()
+
+    for {
+		if p.match(TokenDot) {
+			name := p.consume(TokenIdentifier, "Expect property name after '.'.")
+			expr = &GetExpr{Object: expr, Name: name}
+		} else if p.match(TokenLeftParen) {
+			expr = p.finishCall(expr)
+		} else if p.match(TokenLeftBracket) {
+			index := p.expression()
+			bracket := p.consume(TokenRightBracket, "Expect ']' after index.")
+			expr = &IndexExpr{Object: expr, Bracket: bracket, Index: index}
+		} else {
+			break
+		}
+    }
+
+    return expr
+}
+
+func (p *Parser) finishCall(callee Expr) Expr {
+    var arguments []Expr
+    if !p.check(TokenRightParen) {
+		for {
+			if len(arguments) >= 255 {
+				p.error(p.peek(), "Cannot have more than 255 arguments.")
+			}
+			arguments = append(arguments, p.expression())
+			if !p.match(TokenComma) {
+				break
+			}
+		}
+	}	
+    paren := p.consume(TokenRightParen, "Expect ')' after arguments.")
+    return &Call{Callee: callee, Paren: paren, Arguments: arguments}
+}
+
+func (p *Parser) returnStatement() Stmt {
+    keyword := p.previous()
+    var value Expr
+    if !p.check(TokenSemicolon) {
+        value = p.expression()
+    }
+    p.consume(TokenSemicolon, "Expect ';' after return value.")
+    return &ReturnStmt{Keyword: keyword, Value: value}
+}
+
+func (p *Parser) classDeclaration() Stmt {
+    name := p.consume(TokenIdentifier, "Expect class name.")
+
+    var superclass *Variable
+    if p.match(TokenLess) {
+        p.consume(TokenIdentifier, "Expect superclass name.")
+        superclass = &Variable{Name: p.previous()}
+    }
+
+    p.consume(TokenLeftBrace, "Expect '{' before class body.")
+
+    var methods []*FunStmt
+    for !p.check(TokenRightBrace) && !p.isAtEnd() {
+        // Use type assertion to convert Stmt to *FunStmt
+        if method, ok := p.function("method").(*FunStmt); ok {
+            methods = append(methods, method)
+        }
+    }
+
+    p.consume(TokenRightBrace, "Expect '}' after class body.")
+
+    return &ClassStmt{
+        Name:       name,
+        Superclass: superclass,
+        Methods:    methods,
+    }
+}
@@ -0,0 +1,316 @@
+package lox
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+	"unicode"
+)
+
+const (
+	prompt             = "> "
+	continuationPrompt = "... "
+
+	// maxHistory is the number of lines kept from the history file.
+	maxHistory = 1000
+)
+
+const replHelp = `Enter Lox statements or expressions. Unclosed brackets, strings and
+comments continue onto the next line.
+
+  :load <file>     run a script in the current session
+  :reset           discard every definition and start a fresh session
+  :tokens <code>   show the tokens the scanner produces for code
+  :ast <code>      show the syntax tree for code; locals resolved to an
+                   enclosing scope are shown as name@depth
+  :env             list the bindings in the current environment
+  :time <code>     run code and report how long it took
+  :help            show this message
+  :quit            leave the REPL (Ctrl-D also works)`
+
+// sourceCommands take Lox code as their argument, which may continue over
+// several lines like any other entry.
+var sourceCommands = map[string]bool{":tokens": true, ":ast": true, ":time": true}
+
+// repl reads entries from the user and runs them in one session.
+type repl struct {
+	session     *session
+	editor      *lineEditor
+	historyFile string
+	out         io.Writer
+	stdErr      io.Writer
+}
+
+func newREPL(in io.Reader, out io.Writer, stdErr io.Writer) *repl {
+	editor := newLineEditor(in, out)
+	return &repl{
+		session: newSession(editor.in, out, stdErr),
+		editor:  editor,
+		out:     out,
+		stdErr:  stdErr,
+	}
+}
+
+// RunREPL runs an interactive session, reading entries from in and writing
+// prompts and output to out and errors to stdErr, until the input ends or
+// the user quits. Lines entered are saved to historyFile, and earlier ones
+// loaded from it, unless it is empty.
+func RunREPL(in io.Reader, out io.Writer, stdErr io.Writer, historyFile string) {
+	repl := newREPL(in, out, stdErr)
+	if historyFile != "" {
+		repl.loadHistory(historyFile)
+	}
+	repl.run()
+}
+
+// DefaultHistoryFile is $LOX_HISTORY, or ~/.lox_history when it is unset.
+func DefaultHistoryFile() string {
+	if path := os.Getenv("LOX_HISTORY"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".lox_history")
+}
+
+// loadHistory makes the lines saved in path available for recall, and
+// appends every line entered from now on to it. A missing file is fine.
+func (r *repl) loadHistory(path string) {
+	r.historyFile = path
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.TrimRight(string(contents), "\n"), "\n")
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+	}
+	for _, line := range lines {
+		r.editor.addHistory(line)
+	}
+}
+
+func (r *repl) addHistory(line string) {
+	before := len(r.editor.history)
+	r.editor.addHistory(line)
+	if r.historyFile == "" || len(r.editor.history) == before {
+		return
+	}
+	file, err := os.OpenFile(r.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+	fmt.Fprintln(file, line)
+}
+
+// run reads and runs entries until the input ends or the user quits.
+func (r *repl) run() {
+	for {
+		entry, err := r.readEntry()
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+
+		trimmed := strings.TrimSpace(entry)
+		if strings.HasPrefix(trimmed, ":") {
+			if !r.command(trimmed) {
+				return
+			}
+		} else if trimmed != "" {
+			r.session.runLine(entry)
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// readEntry reads lines until they form a complete entry: a meta-command,
+// or source in which every bracket, string and block comment is closed.
+// If the input ends part-way through an entry, what was read is returned
+// along with io.EOF so that the error is still reported.
+func (r *repl) readEntry() (string, error) {
+	var lines []string
+	for {
+		linePrompt := prompt
+		if len(lines) > 0 {
+			linePrompt = continuationPrompt
+		}
+
+		line, err := r.editor.readLine(linePrompt)
+		if err != nil {
+			return strings.Join(lines, "\n"), err
+		}
+		r.addHistory(line)
+		lines = append(lines, line)
+
+		entry := strings.Join(lines, "\n")
+		source := entry
+		if name, argument := splitCommand(entry); name != "" {
+			if !sourceCommands[name] {
+				return entry, nil
+			}
+			source = argument
+		}
+		if inputComplete(source) {
+			return entry, nil
+		}
+	}
+}
+
+// inputComplete reports whether source can be run as it stands: every '(',
+// '{' and '[' has been closed and no string or block comment is left open.
+// Stray closing brackets count as complete so the parser can report them.
+func inputComplete(source string) bool {
+	scanner := NewScanner(source, io.Discard)
+	tokens := scanner.ScanTokens()
+	if scanner.unterminated {
+		return false
+	}
+
+	depth := 0
+	for _, token := range tokens {
+		switch token.TokenType {
+		case TokenLeftParen, TokenLeftBrace, TokenLeftBracket:
+			depth++
+		case TokenRightParen, TokenRightBrace, TokenRightBracket:
+			depth--
+		}
+	}
+	return depth <= 0
+}
+
+// splitCommand splits a meta-command entry into the command name and its
+// argument. name is empty if entry is not a meta-command.
+func splitCommand(entry string) (name string, argument string) {
+	entry = strings.TrimSpace(entry)
+	if !strings.HasPrefix(entry, ":") {
+		return "", ""
+	}
+	if end := strings.IndexFunc(entry, unicode.IsSpace); end >= 0 {
+		return entry[:end], strings.TrimSpace(entry[end:])
+	}
+	return entry, ""
+}
+
+// command runs a meta-command and reports whether the REPL should go on.
+func (r *repl) command(input string) bool {
+	name, argument := splitCommand(input)
+	if sourceCommands[name] && argument == "" {
+		fmt.Fprintf(r.stdErr, "Usage: %s <code>\n", name)
+		return true
+	}
+
+	switch name {
+	case ":quit", ":q", ":exit":
+		return false
+	case ":help":
+		fmt.Fprintln(r.out, replHelp)
+	case ":reset":
+		r.session = newSession(r.editor.in, r.out, r.stdErr)
+	case ":load":
+		if argument == "" {
+			fmt.Fprintln(r.stdErr, "Usage: :load <file>")
+			break
+		}
+		if err := r.session.load(argument); err != nil {
+			fmt.Fprintf(r.stdErr, "Error reading file: %v\n", err)
+		}
+	case ":tokens":
+		r.printTokens(argument)
+	case ":ast":
+		r.printAst(argument)
+	case ":env":
+		r.printEnvironment()
+	case ":time":
+		start := time.Now()
+		r.session.runLine(argument)
+		fmt.Fprintf(r.out, "Elapsed: %v\n", time.Since(start))
+	default:
+		fmt.Fprintf(r.stdErr, "Unknown command '%s'. Type :help for a list.\n", name)
+	}
+	return true
+}
+
+func (r *repl) printTokens(source string) {
+	tokens, _ := r.session.interpreter.scan(source)
+	// Show offsets into the entry rather than into the session's sources.
+	file, _ := r.session.interpreter.sources.file(tokens[len(tokens)-1].Start)
+
+	writer := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "LINE\tSTART\tCOLUMN\tTYPE\tLEXEME\tLITERAL")
+	for _, token := range tokens {
+		literal := ""
+		if token.Literal != nil {
+			literal = fmt.Sprintf("%v", token.Literal)
+		}
+		fmt.Fprintf(writer, "%d\t%d\t%d\t%s\t%s\t%s\n", token.Line, token.Start-file.base, token.Column, token.TokenType, token.Lexeme, literal)
+	}
+	writer.Flush()
+}
+
+// printAst parses and resolves source without running it. Resolution uses a
+// scratch interpreter so that the session is left untouched.
+func (r *repl) printAst(source string) {
+	tokens, err := r.session.interpreter.scan(terminateStatement(source))
+	if err != nil {
+		return
+	}
+	statements, err := NewParser(tokens, nil).ParseStatements()
+	if err != nil {
+		r.session.interpreter.report(err)
+		return
+	}
+
+	scratch := NewInterpreter(nil, nil, nil)
+	resolver := NewResolver(scratch)
+	resolver.Resolve(statements)
+	r.session.interpreter.report(ResolveErrors(resolver.Errors()))
+	fmt.Fprintln(r.out, NewAstPrinter(scratch.locals).Print(statements))
+}
+
+// printEnvironment lists the bindings visible from the session's current
+// environment, innermost scope first, with the kind of value each holds.
+func (r *repl) printEnvironment() {
+	writer := tabwriter.NewWriter(r.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tKIND\tVALUE")
+	for env := r.session.interpreter.environment; env != nil; env = env.parent {
+		names := make([]string, 0, len(env.values))
+		for name := range env.values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			value := env.values[name]
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", name, valueKind(value), stringify(value))
+		}
+	}
+	writer.Flush()
+}
+
+// valueKind describes what sort of value a binding holds.
+func valueKind(value interface{}) string {
+	switch value.(type) {
+	case *LoxFunction:
+		return "function"
+	case *LoxClass:
+		return "class"
+	case *LoxModule:
+		return "module"
+	case Callable:
+		return "native"
+	default:
+		return "value"
+	}
+}
@@ -1,12 +1,40 @@
-package main
+package lox
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type Resolver struct {
 	interpreter    *Interpreter
 	scopes         []map[string]bool
 	currentFunction FunctionType
 	currentClass   ClassType
+	loopDepth      int
+	errors         []ResolveError
+}
+
+// ResolveError is a static error found by the resolver, such as a 'return'
+// outside of a function, reported at the offending token.
+type ResolveError struct {
+	Token   Token
+	Message string
+}
+
+func (e ResolveError) Error() string {
+	return fmt.Sprintf("[line %d, column %d] Error at '%s': %s", e.Token.Line, e.Token.Column, e.Token.Lexeme, e.Message)
+}
+
+// ResolveErrors is every error found while resolving a program, in the
+// order they were found.
+type ResolveErrors []ResolveError
+
+func (e ResolveErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, resolveError := range e {
+		messages[i] = resolveError.Error()
+	}
+	return strings.Join(messages, "\n")
 }
 
 func NewResolver(interpreter *Interpreter) *Resolver {
@@ -17,12 +45,23 @@ func NewResolver(interpreter *Interpreter) *Resolver {
 	}
 }
 
+// Resolve resolves statements. Errors don't stop resolution; they are
+// collected and available from Errors afterwards.
 func (r *Resolver) Resolve(statements []Stmt) {
 	for _, statement := range statements {
 		r.resolveStatement(statement)
 	}
 }
 
+// Errors returns the errors found so far.
+func (r *Resolver) Errors() []ResolveError {
+	return r.errors
+}
+
+func (r *Resolver) error(token Token, message string) {
+	r.errors = append(r.errors, ResolveError{Token: token, Message: message})
+}
+
 func (r *Resolver) resolveStatement(stmt Stmt) {
 	stmt.Accept(r)
 }
@@ -45,7 +84,7 @@ func (r *Resolver) declare(name Token) {
 	}
 	scope := r.scopes[len(r.scopes)-1]
 	if _, exists := scope[name.Lexeme]; exists {
-		panic(fmt.Sprintf("Variable with name '%s' already declared in this scope.", name.Lexeme))
+		r.error(name, fmt.Sprintf("Variable with name '%s' already declared in this scope.", name.Lexeme))
 	}
 	scope[name.Lexeme] = false
 }
@@ -67,17 +106,20 @@ func (r *Resolver) resolveLocal(expr Expr, name Token) {
 	// Not found; assume it’s global.
 }
 
-func (r *Resolver) resolveFunction(stmt *FunStmt, functionType FunctionType) {
+func (r *Resolver) resolveFunction(params []Token, body []Stmt, functionType FunctionType) {
     enclosingFunction := r.currentFunction
+    enclosingLoopDepth := r.loopDepth
     r.currentFunction = functionType
+    r.loopDepth = 0
     r.beginScope()
-    for _, param := range stmt.Params {
+    for _, param := range params {
         r.declare(param)
         r.define(param)
     }
-    r.Resolve(stmt.Body)
+    r.Resolve(body)
     r.endScope()
     r.currentFunction = enclosingFunction
+    r.loopDepth = enclosingLoopDepth
 }
 
 // Statement visitors
@@ -118,9 +160,12 @@ func (r *Resolver) VisitPrintStmt(stmt *PrintStmt) interface{} {
 
 func (r *Resolver) VisitReturnStmt(stmt *ReturnStmt) interface{} {
 	if r.currentFunction == FunctionNone {
-		panic("Cannot return from top-level code.")
+		r.error(stmt.Keyword, "Cannot return from top-level code.")
 	}
 	if stmt.Value != nil {
+		if r.currentFunction == FunctionInitializer {
+			r.error(stmt.Keyword, "Cannot return a value from an initializer.")
+		}
 		r.resolveExpression(stmt.Value)
 	}
 	return nil
@@ -128,18 +173,33 @@ func (r *Resolver) VisitReturnStmt(stmt *ReturnStmt) interface{} {
 
 func (r *Resolver) VisitWhileStmt(stmt *WhileStmt) interface{} {
 	r.resolveExpression(stmt.Condition)
+	r.loopDepth++
 	r.resolveStatement(stmt.Body)
+	r.loopDepth--
+	if stmt.Increment != nil {
+		r.resolveExpression(stmt.Increment)
+	}
 	return nil
 }
 
 func (r *Resolver) VisitFunStmt(stmt *FunStmt) interface{} {
     r.declare(stmt.Name)
     r.define(stmt.Name)
-    r.resolveFunction(stmt, FunctionFunction)
+    r.resolveFunction(stmt.Params, stmt.Body, FunctionFunction)
     return nil
 }
 
 func (r *Resolver) VisitBreakStmt(stmt *BreakStmt) interface{} {
+	if r.loopDepth == 0 {
+		r.error(stmt.Keyword, "Cannot use 'break' outside of a loop.")
+	}
+	return nil
+}
+
+func (r *Resolver) VisitContinueStmt(stmt *ContinueStmt) interface{} {
+	if r.loopDepth == 0 {
+		r.error(stmt.Keyword, "Cannot use 'continue' outside of a loop.")
+	}
 	return nil
 }
 
@@ -150,6 +210,12 @@ func (r *Resolver) VisitBinaryExpr(expr *Binary) interface{} {
 	return nil
 }
 
+func (r *Resolver) VisitLogicalExpr(expr *Logical) interface{} {
+	r.resolveExpression(expr.Left)
+	r.resolveExpression(expr.Right)
+	return nil
+}
+
 func (r *Resolver) VisitGroupingExpr(expr *Grouping) interface{} {
 	r.resolveExpression(expr.Expression)
 	return nil
@@ -168,7 +234,7 @@ func (r *Resolver) VisitVariableExpr(expr *Variable) interface{} {
 	if len(r.scopes) > 0 {
 		scope := r.scopes[len(r.scopes)-1]
 		if defined, exists := scope[expr.Name.Lexeme]; exists && !defined {
-			panic(fmt.Sprintf("Cannot read local variable '%s' in its own initializer.", expr.Name.Lexeme))
+			r.error(expr.Name, fmt.Sprintf("Cannot read local variable '%s' in its own initializer.", expr.Name.Lexeme))
 		}
 	}
 	r.resolveLocal(expr, expr.Name)
@@ -226,7 +292,7 @@ func (r *Resolver) VisitClassStmt(stmt *ClassStmt) interface{} {
         if method.Name.Lexeme == "init" {
             declaration = FunctionInitializer
         }
-        r.resolveFunction(method, declaration)
+        r.resolveFunction(method.Params, method.Body, declaration)
     }
 
     r.endScope()
@@ -240,7 +306,7 @@ func (r *Resolver) VisitClassStmt(stmt *ClassStmt) interface{} {
 
 func (r *Resolver) VisitThisExpr(expr *ThisExpr) interface{} {
     if r.currentClass == ClassNone {
-        panic("Cannot use 'this' outside of a class.")
+        r.error(expr.Keyword, "Cannot use 'this' outside of a class.")
     }
     r.resolveLocal(expr, expr.Keyword)
     return nil
@@ -248,9 +314,9 @@ func (r *Resolver) VisitThisExpr(expr *ThisExpr) interface{} {
 
 func (r *Resolver) VisitSuperExpr(expr *SuperExpr) interface{} {
     if r.currentClass == ClassNone {
-        panic("Cannot use 'super' outside of a class.")
+        r.error(expr.Keyword, "Cannot use 'super' outside of a class.")
     } else if r.currentClass != ClassSubclass {
-        panic("Cannot use 'super' in a class with no superclass.")
+        r.error(expr.Keyword, "Cannot use 'super' in a class with no superclass.")
     }
     r.resolveLocal(expr, expr.Keyword)
     return nil
@@ -265,4 +331,82 @@ func (r *Resolver) VisitSetExpr(expr *SetExpr) interface{} {
     r.resolveExpression(expr.Value)
     r.resolveExpression(expr.Object)
     return nil
-}
\ No newline at end of file
+}
+
+func (r *Resolver) VisitListExpr(expr *ListExpr) interface{} {
+	for _, element := range expr.Elements {
+		r.resolveExpression(element)
+	}
+	return nil
+}
+
+func (r *Resolver) VisitIndexExpr(expr *IndexExpr) interface{} {
+	r.resolveExpression(expr.Object)
+	r.resolveExpression(expr.Index)
+	return nil
+}
+
+func (r *Resolver) VisitIndexSetExpr(expr *IndexSetExpr) interface{} {
+	r.resolveExpression(expr.Value)
+	r.resolveExpression(expr.Object)
+	r.resolveExpression(expr.Index)
+	return nil
+}
+
+func (r *Resolver) VisitMapExpr(expr *MapExpr) interface{} {
+	for i := range expr.Keys {
+		r.resolveExpression(expr.Keys[i])
+		r.resolveExpression(expr.Values[i])
+	}
+	return nil
+}
+
+func (r *Resolver) VisitConditionalExpr(expr *Conditional) interface{} {
+	r.resolveExpression(expr.Condition)
+	r.resolveExpression(expr.ThenBranch)
+	r.resolveExpression(expr.ElseBranch)
+	return nil
+}
+
+func (r *Resolver) VisitLambdaExpr(expr *Lambda) interface{} {
+	r.resolveFunction(expr.Params, expr.Body, FunctionFunction)
+	return nil
+}
+
+func (r *Resolver) VisitImportStmt(stmt *ImportStmt) interface{} {
+	r.declare(stmt.Name)
+	r.define(stmt.Name)
+	return nil
+}
+
+func (r *Resolver) VisitTypeAliasStmt(stmt *TypeAliasStmt) interface{} {
+	return nil
+}
+
+func (r *Resolver) VisitThrowStmt(stmt *ThrowStmt) interface{} {
+	r.resolveExpression(stmt.Value)
+	return nil
+}
+
+func (r *Resolver) VisitTryStmt(stmt *TryStmt) interface{} {
+	r.beginScope()
+	r.Resolve(stmt.TryBlock)
+	r.endScope()
+
+	if stmt.CatchName != nil {
+		// The exception variable shares a scope with the catch body, as
+		// parameters do with a function body.
+		r.beginScope()
+		r.declare(*stmt.CatchName)
+		r.define(*stmt.CatchName)
+		r.Resolve(stmt.CatchBlock)
+		r.endScope()
+	}
+
+	if stmt.FinallyBlock != nil {
+		r.beginScope()
+		r.Resolve(stmt.FinallyBlock)
+		r.endScope()
+	}
+	return nil
+}
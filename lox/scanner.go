@@ -1,25 +1,63 @@
-package main
+package lox
 
 import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
 // Scanner convert a source text
 // into a slice of Token-s
 type Scanner struct {
-	start   int
-	current int
-	line    int
-	source  string
-	tokens  []Token
-	stdErr  io.Writer
+	start        int
+	current      int
+	line         int
+	startLine    int // line the current lexeme starts on
+	lineStart    int // index of the first character of the current line
+	startColumn  int // column the current lexeme starts at
+	base         int // added to offsets in tokens and errors; see sourceSet
+	source       string
+	tokens       []Token
+	stdErr       io.Writer
+	errors       ScanErrors
+	unterminated bool // source ended inside a string or block comment
 }
 
-// NewScanner returns a new Scanner
+// NewScanner returns a new Scanner. Errors are written to stdErr as they
+// are found, unless it is nil, and are also available from Errors.
 func NewScanner(source string, stdErr io.Writer) *Scanner {
-	return &Scanner{source: source, stdErr: stdErr}
+	return &Scanner{source: source, line: 1, stdErr: stdErr}
+}
+
+// ScanError is a lexical error, such as an unexpected character. Span covers
+// the offending text.
+type ScanError struct {
+	Line    int
+	Column  int
+	Span    Span
+	Message string
+}
+
+func (e ScanError) Error() string {
+	return fmt.Sprintf("[line %d] Error: %s", e.Line, e.Message)
+}
+
+// ScanErrors is every error found while scanning a source text.
+type ScanErrors []ScanError
+
+func (e ScanErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, scanError := range e {
+		messages[i] = scanError.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// Errors returns the errors found while scanning.
+func (s *Scanner) Errors() ScanErrors {
+	return s.errors
 }
 
 // ScanTokens returns a slice of tokens representing the source text
@@ -27,10 +65,12 @@ func (s *Scanner) ScanTokens() []Token {
 	for !s.isAtEnd() {
 		// we're at the beginning of the next lexeme
 		s.start = s.current
+		s.startLine = s.line
+		s.startColumn = s.column(s.current)
 		s.scanToken()
 	}
 
-	s.tokens = append(s.tokens, Token{TokenType: TokenEof, Line: s.line})
+	s.tokens = append(s.tokens, Token{TokenType: TokenEof, Line: s.line, Column: s.column(s.current), Start: s.base + s.current})
 	return s.tokens
 }
 
@@ -66,7 +106,11 @@ func (s *Scanner) scanToken() {
 	case '?':
 		s.addToken(TokenQuestionMark)
 	case '|':
-		s.addToken(TokenPipe)
+		if s.match('>') {
+			s.addToken(TokenPipeGreater)
+		} else {
+			s.addToken(TokenPipe)
+		}
 
 	// with look-ahead
 	case '!':
@@ -81,6 +125,8 @@ func (s *Scanner) scanToken() {
 		var nextToken TokenType
 		if s.match('=') {
 			nextToken = TokenEqualEqual
+		} else if s.match('>') {
+			nextToken = TokenArrow
 		} else {
 			nextToken = TokenEqual
 		}
@@ -109,17 +155,20 @@ func (s *Scanner) scanToken() {
 			}
 		} else if s.match('*') {
 			// Block comment
+			closed := false
 			for !s.isAtEnd() {
 				if s.peek() == '*' && s.peekNext() == '/' {
 					s.advance() // consume *
 					s.advance() // consume /
+					closed = true
 					break
 				} else if s.peek() == '\n' {
-					s.line++
+					s.newline(s.current + 1)
 				}
 				s.advance()
 			}
-			if s.isAtEnd() {
+			if !closed {
+				s.unterminated = true
 				s.error("Unterminated block comment.")
 			}
 		} else {
@@ -132,7 +181,7 @@ func (s *Scanner) scanToken() {
 	case '\t':
 
 	case '\n':
-		s.line++
+		s.newline(s.current)
 
 	// string
 	case '"':
@@ -149,6 +198,18 @@ func (s *Scanner) scanToken() {
 	}
 }
 
+// newline records that a new line begins at index lineStart.
+func (s *Scanner) newline(lineStart int) {
+	s.line++
+	s.lineStart = lineStart
+}
+
+// column returns the 1-based column of the character at offset, counting
+// characters rather than bytes.
+func (s *Scanner) column(offset int) int {
+	return utf8.RuneCountInString(s.source[s.lineStart:offset]) + 1
+}
+
 func (s *Scanner) isAtEnd() bool {
 	return s.current >= len(s.source)
 }
@@ -169,8 +230,9 @@ func (s *Scanner) addTokenWithLiteral(tokenType TokenType, literal interface{})
 		TokenType: tokenType,
 		Lexeme:    text,
 		Literal:   literal,
-		Line:      s.line,
-		Start:     s.start}
+		Line:      s.startLine,
+		Column:    s.startColumn,
+		Start:     s.base + s.start}
 	s.tokens = append(s.tokens, token)
 }
 
@@ -190,12 +252,13 @@ func (s *Scanner) match(expected rune) bool {
 func (s *Scanner) string() {
 	for s.peek() != '"' && !s.isAtEnd() {
 		if s.peek() == '\n' {
-			s.line++
+			s.newline(s.current + 1)
 		}
 		s.advance()
 	}
 
 	if s.isAtEnd() {
+		s.unterminated = true
 		s.error("Unterminated string.")
 		return
 	}
@@ -264,7 +327,11 @@ var keywords = map[string]TokenType{
 	"while":    TokenWhile,
 	"break":    TokenBreak,
 	"continue": TokenContinue,
-	"type":     TokenTypeType,
+	"throw":    TokenThrow,
+	"try":      TokenTry,
+	"catch":    TokenCatch,
+	"finally":  TokenFinally,
+	"import":   TokenImport,
 }
 
 func (s *Scanner) identifier() {
@@ -280,10 +347,35 @@ func (s *Scanner) identifier() {
 	s.addToken(tokenType)
 }
 
+// isIdentifier reports whether text would scan as a single identifier.
+func isIdentifier(text string) bool {
+	if text == "" {
+		return false
+	}
+	s := &Scanner{}
+	for i, char := range text {
+		if !s.isAlpha(char) && (i == 0 || !s.isDigit(char)) {
+			return false
+		}
+	}
+	_, isKeyword := keywords[text]
+	return !isKeyword
+}
+
 func (s *Scanner) isAlphaNumeric(char rune) bool {
 	return s.isAlpha(char) || s.isDigit(char)
 }
 
+// error records an error in the current lexeme.
 func (s *Scanner) error(message string) {
-	_, _ = s.stdErr.Write([]byte(fmt.Sprintf("[line %d] Error: %s\n", s.line, message)))
+	scanError := ScanError{
+		Line:    s.startLine,
+		Column:  s.startColumn,
+		Span:    Span{Start: s.base + s.start, End: s.base + s.current},
+		Message: message,
+	}
+	s.errors = append(s.errors, scanError)
+	if s.stdErr != nil {
+		_, _ = s.stdErr.Write([]byte(scanError.Error() + "\n"))
+	}
 }
@@ -0,0 +1,56 @@
+package lox
+
+import (
+	"bufio"
+	"io"
+	"io/fs"
+)
+
+// session owns the state that outlives a single REPL entry: one
+// interpreter, with its globals, classes, functions, resolved locals and
+// type-checker scope. The REPL keeps one session for its whole lifetime.
+type session struct {
+	interpreter *Interpreter
+}
+
+// newSession creates a session whose programs read input() lines from
+// stdin. The REPL passes its line editor's reader, so that the two take
+// turns on one buffer rather than each reading ahead of the other.
+func newSession(stdin *bufio.Reader, stdout io.Writer, stdErr io.Writer) *session {
+	return &session{
+		interpreter: New(Options{Stdin: stdin, Stdout: stdout, Stderr: stdErr}),
+	}
+}
+
+// runLine runs one entry of REPL input, which may span several lines. A
+// missing final ';' is supplied so that bare expressions can be typed as-is.
+// Any error is reported and the session carries on with everything defined
+// before it intact.
+func (s *session) runLine(line string) {
+	_ = s.interpreter.run(terminateStatement(line), true)
+}
+
+// terminateStatement appends a ';' to source unless it already ends with
+// one or with a block.
+func terminateStatement(source string) string {
+	tokens := NewScanner(source, io.Discard).ScanTokens()
+	if len(tokens) > 1 {
+		last := tokens[len(tokens)-2].TokenType
+		if last != TokenSemicolon && last != TokenRightBrace {
+			// On a line of its own, so a trailing comment can't swallow it.
+			source += "\n;"
+		}
+	}
+	return source
+}
+
+// load runs the script at path in the session, resolving its imports
+// relative to the script. Errors are reported as in runLine.
+func (s *session) load(path string) error {
+	if err := s.interpreter.RunFile(path); err != nil {
+		if _, unreadable := err.(*fs.PathError); unreadable {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package lox
+
+// Span is a range of source text given as byte offsets, from Start up to but
+// not including End.
+type Span struct {
+	Start int
+	End   int
+}
+
+// Empty reports whether the span covers no text.
+func (s Span) Empty() bool {
+	return s.End <= s.Start
+}
+
+// Union returns the smallest span covering both s and other. An empty span
+// contributes nothing.
+func (s Span) Union(other Span) Span {
+	if other.Empty() {
+		return s
+	}
+	if s.Empty() {
+		return other
+	}
+	if other.Start < s.Start {
+		s.Start = other.Start
+	}
+	if other.End > s.End {
+		s.End = other.End
+	}
+	return s
+}
+
+// Span returns the source text covered by the token's lexeme.
+func (t Token) Span() Span {
+	return Span{Start: t.Start, End: t.Start + len(t.Lexeme)}
+}
+
+// SpanOf returns the source text an expression was parsed from. Nodes the
+// parser synthesizes, such as the condition of 'for (;;)', have an empty span.
+func SpanOf(expr Expr) Span {
+	switch e := expr.(type) {
+	case *Binary:
+		return SpanOf(e.Left).Union(SpanOf(e.Right))
+	case *Logical:
+		return SpanOf(e.Left).Union(SpanOf(e.Right))
+	case *Grouping:
+		return e.Paren.Span().Union(e.Closing.Span())
+	case *Literal:
+		return e.Token.Span()
+	case *Unary:
+		return e.Operator.Span().Union(SpanOf(e.Right))
+	case *Variable:
+		return e.Name.Span()
+	case *Assign:
+		return e.Name.Span().Union(SpanOf(e.Value))
+	case *Call:
+		span := SpanOf(e.Callee).Union(e.Paren.Span())
+		for _, argument := range e.Arguments {
+			span = span.Union(SpanOf(argument))
+		}
+		return span
+	case *GetExpr:
+		return SpanOf(e.Object).Union(e.Name.Span())
+	case *SetExpr:
+		return SpanOf(e.Object).Union(SpanOf(e.Value))
+	case *ThisExpr:
+		return e.Keyword.Span()
+	case *SuperExpr:
+		return e.Keyword.Span().Union(e.Method.Span())
+	case *ListExpr:
+		return e.Bracket.Span().Union(e.Closing.Span())
+	case *IndexExpr:
+		return SpanOf(e.Object).Union(e.Bracket.Span())
+	case *IndexSetExpr:
+		return SpanOf(e.Object).Union(SpanOf(e.Value))
+	case *MapExpr:
+		return e.Brace.Span().Union(e.Closing.Span())
+	case *Conditional:
+		return SpanOf(e.Condition).Union(SpanOf(e.ElseBranch))
+	case *Lambda:
+		return e.Keyword.Span().Union(e.Paren.Span()).Union(e.End.Span())
+	}
+	return Span{}
+}
@@ -1,4 +1,4 @@
-package main
+package lox
 
 // Stmt is the interface for all statement types.
 type Stmt interface {
@@ -14,9 +14,14 @@ type StmtVisitor interface {
 	VisitIfStmt(stmt *IfStmt) interface{}
 	VisitWhileStmt(stmt *WhileStmt) interface{}
 	VisitBreakStmt(stmt *BreakStmt) interface{}
+	VisitContinueStmt(stmt *ContinueStmt) interface{}
 	VisitFunStmt(stmt *FunStmt) interface{}
 	VisitReturnStmt(stmt *ReturnStmt) interface{}
 	VisitClassStmt(stmt *ClassStmt) interface{}
+	VisitThrowStmt(stmt *ThrowStmt) interface{}
+	VisitTryStmt(stmt *TryStmt) interface{}
+	VisitImportStmt(stmt *ImportStmt) interface{}
+	VisitTypeAliasStmt(stmt *TypeAliasStmt) interface{}
 }
 
 // ExpressionStmt represents an expression as a statement.
@@ -37,9 +42,11 @@ func (stmt *PrintStmt) Accept(visitor StmtVisitor) interface{} {
 	return visitor.VisitPrintStmt(stmt)
 }
 
-// VarStmt represents a variable declaration.
+// VarStmt represents a variable declaration. Type is nil when the variable
+// is not annotated.
 type VarStmt struct {
 	Name        Token
+	Type        *TypeAnnotation
 	Initializer Expr
 }
 
@@ -65,26 +72,43 @@ func (stmt *IfStmt) Accept(visitor StmtVisitor) interface{} {
     return visitor.VisitIfStmt(stmt)
 }
 
+// WhileStmt represents a while loop. Increment is only set for desugared
+// for loops and runs after every iteration, including ones cut short by continue.
 type WhileStmt struct {
     Condition Expr
     Body      Stmt
+    Increment Expr
 }
 func (stmt *WhileStmt) Accept(visitor StmtVisitor) interface{} {
     return visitor.VisitWhileStmt(stmt)
 }
 
 // BreakStmt represents a break statement.
-type BreakStmt struct {}
+type BreakStmt struct {
+    Keyword Token
+}
 
 func (stmt *BreakStmt) Accept(visitor StmtVisitor) interface{} {
     return visitor.VisitBreakStmt(stmt)
 }
 
-// FunStmt represents a function declaration.
+// ContinueStmt represents a continue statement.
+type ContinueStmt struct {
+    Keyword Token
+}
+
+func (stmt *ContinueStmt) Accept(visitor StmtVisitor) interface{} {
+    return visitor.VisitContinueStmt(stmt)
+}
+
+// FunStmt represents a function declaration. ParamTypes parallels Params,
+// with nil entries for unannotated parameters.
 type FunStmt struct {
-    Name   Token
-    Params []Token
-    Body   []Stmt
+    Name       Token
+    Params     []Token
+    ParamTypes []*TypeAnnotation
+    ReturnType *TypeAnnotation
+    Body       []Stmt
 }
 
 func (stmt *FunStmt) Accept(visitor StmtVisitor) interface{} {
@@ -108,4 +132,57 @@ type ClassStmt struct {
 
 func (stmt *ClassStmt) Accept(visitor StmtVisitor) interface{} {
     return visitor.VisitClassStmt(stmt)
-}
\ No newline at end of file
+}
+
+// ThrowStmt represents a throw statement.
+type ThrowStmt struct {
+	Keyword Token
+	Value   Expr
+}
+
+func (stmt *ThrowStmt) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitThrowStmt(stmt)
+}
+
+// TryStmt represents a try statement. CatchName is nil when there is no catch
+// clause; FinallyBlock is nil when there is no finally clause.
+type TryStmt struct {
+	TryBlock     []Stmt
+	CatchName    *Token
+	CatchBlock   []Stmt
+	FinallyBlock []Stmt
+}
+
+func (stmt *TryStmt) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitTryStmt(stmt)
+}
+
+
+// ImportStmt represents an import statement (e.g., import "math.lox" as m;).
+// Name is the binding the module is stored under; without an 'as' clause it
+// is derived from the file name.
+type ImportStmt struct {
+	Keyword Token
+	Path    Token
+	Name    Token
+}
+
+func (stmt *ImportStmt) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitImportStmt(stmt)
+}
+
+// TypeAliasStmt represents a type alias declaration (e.g., type Id = number | string;).
+type TypeAliasStmt struct {
+	Name Token
+	Type *TypeAnnotation
+}
+
+func (stmt *TypeAliasStmt) Accept(visitor StmtVisitor) interface{} {
+	return visitor.VisitTypeAliasStmt(stmt)
+}
+
+// TypeAnnotation is an optional static type written in the source, a union
+// of one or more type names (e.g., string | nil).
+type TypeAnnotation struct {
+	Names []Token
+}
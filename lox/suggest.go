@@ -0,0 +1,79 @@
+package lox
+
+import "sort"
+
+// didYouMean returns a hint naming the candidate closest to name, such as
+// " Did you mean 'count'?", ready to append to an error message. It is empty
+// if no candidate is close enough to be a likely typo.
+func didYouMean(name string, candidates []string) string {
+	if match := closestMatch(name, candidates); match != "" {
+		return " Did you mean '" + match + "'?"
+	}
+	return ""
+}
+
+// closestMatch returns the candidate with the smallest edit distance from
+// name, allowing about one edit for every three characters. Ties go to the
+// alphabetically first candidate, so that hints don't depend on map order.
+func closestMatch(name string, candidates []string) string {
+	sorted := append([]string{}, candidates...)
+	sort.Strings(sorted)
+
+	length := len([]rune(name))
+	limit := length / 3
+	if limit < 1 {
+		limit = 1
+	}
+
+	best, bestDistance := "", limit+1
+	for _, candidate := range sorted {
+		if candidate == name {
+			continue
+		}
+		distance := editDistance(name, candidate)
+		// Replacing every character isn't a typo.
+		if distance < bestDistance && distance < length {
+			best, bestDistance = candidate, distance
+		}
+	}
+	return best
+}
+
+// editDistance counts the insertions, deletions, substitutions and swaps of
+// adjacent characters needed to turn a into b.
+func editDistance(a, b string) int {
+	source, target := []rune(a), []rune(b)
+	// rows[i][j] is the distance between source[:i] and target[:j].
+	rows := make([][]int, len(source)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(target)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+
+	for i := 1; i <= len(source); i++ {
+		for j := 1; j <= len(target); j++ {
+			cost := 1
+			if source[i-1] == target[j-1] {
+				cost = 0
+			}
+			distance := minimum(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && source[i-1] == target[j-2] && source[i-2] == target[j-1] {
+				distance = minimum(distance, rows[i-2][j-2]+1)
+			}
+			rows[i][j] = distance
+		}
+	}
+	return rows[len(source)][len(target)]
+}
+
+func minimum(first int, rest ...int) int {
+	for _, value := range rest {
+		if value < first {
+			first = value
+		}
+	}
+	return first
+}
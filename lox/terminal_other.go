@@ -0,0 +1,15 @@
+//go:build !linux
+
+package lox
+
+import "errors"
+
+// isTerminal reports whether fd refers to a terminal. Line editing is only
+// supported on Linux; elsewhere the REPL reads plain lines.
+func isTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
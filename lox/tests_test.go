@@ -0,0 +1,2491 @@
+package lox
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		hasError bool
+	}{
+		{
+			name:  "single character tokens",
+			input: "( ) { } , . - + ; * [ ]",
+			expected: []string{
+				fmt.Sprintf("%d ( %v", TokenLeftParen, nil),
+				fmt.Sprintf("%d ) %v", TokenRightParen, nil),
+				fmt.Sprintf("%d { %v", TokenLeftBrace, nil),
+				fmt.Sprintf("%d } %v", TokenRightBrace, nil),
+				fmt.Sprintf("%d , %v", TokenComma, nil),
+				fmt.Sprintf("%d . %v", TokenDot, nil),
+				fmt.Sprintf("%d - %v", TokenMinus, nil),
+				fmt.Sprintf("%d + %v", TokenPlus, nil),
+				fmt.Sprintf("%d ; %v", TokenSemicolon, nil),
+				fmt.Sprintf("%d * %v", TokenStar, nil),
+				fmt.Sprintf("%d [ %v", TokenLeftBracket, nil),
+				fmt.Sprintf("%d ] %v", TokenRightBracket, nil),
+			},
+			hasError: false,
+		},
+		{
+			name:  "numbers",
+			input: "123 45.67",
+			expected: []string{
+				fmt.Sprintf("%d 123 %v", TokenNumber, float64(123)),
+				fmt.Sprintf("%d 45.67 %v", TokenNumber, 45.67),
+			},
+			hasError: false,
+		},
+		{
+			name:  "identifiers",
+			input: "varName abc123 _test",
+			expected: []string{
+				fmt.Sprintf("%d varName %v", TokenIdentifier, nil),
+				fmt.Sprintf("%d abc123 %v", TokenIdentifier, nil),
+				fmt.Sprintf("%d _test %v", TokenIdentifier, nil),
+			},
+			hasError: false,
+		},
+		{
+			name:  "string literals",
+			input: "\"hello\" \"world\"",
+			expected: []string{
+				fmt.Sprintf("%d \"hello\" %v", TokenString, "hello"),
+				fmt.Sprintf("%d \"world\" %v", TokenString, "world"),
+			},
+			hasError: false,
+		},
+		{
+			name:  "operators",
+			input: "! != == > >= < <=",
+			expected: []string{
+				fmt.Sprintf("%d ! %v", TokenBang, nil),
+				fmt.Sprintf("%d != %v", TokenBangEqual, nil),
+				fmt.Sprintf("%d == %v", TokenEqualEqual, nil),
+				fmt.Sprintf("%d > %v", TokenGreater, nil),
+				fmt.Sprintf("%d >= %v", TokenGreaterEqual, nil),
+				fmt.Sprintf("%d < %v", TokenLess, nil),
+				fmt.Sprintf("%d <= %v", TokenLessEqual, nil),
+			},
+			hasError: false,
+		},
+		{
+			name:  "pipe operators",
+			input: "| |> ||>",
+			expected: []string{
+				fmt.Sprintf("%d | %v", TokenPipe, nil),
+				fmt.Sprintf("%d |> %v", TokenPipeGreater, nil),
+				fmt.Sprintf("%d | %v", TokenPipe, nil),
+				fmt.Sprintf("%d |> %v", TokenPipeGreater, nil),
+			},
+			hasError: false,
+		},
+		{
+			name:  "keywords",
+			input: "and class else if nil or true false var while",
+			expected: []string{
+				fmt.Sprintf("%d and %v", TokenAnd, nil),
+				fmt.Sprintf("%d class %v", TokenClass, nil),
+				fmt.Sprintf("%d else %v", TokenElse, nil),
+				fmt.Sprintf("%d if %v", TokenIf, nil),
+				fmt.Sprintf("%d nil %v", TokenNil, nil),
+				fmt.Sprintf("%d or %v", TokenOr, nil),
+				fmt.Sprintf("%d true %v", TokenTrue, nil),
+				fmt.Sprintf("%d false %v", TokenFalse, nil),
+				fmt.Sprintf("%d var %v", TokenVar, nil),
+				fmt.Sprintf("%d while %v", TokenWhile, nil),
+			},
+			hasError: false,
+		},
+		{
+			name:  "single line comments",
+			input: "// this is a comment\n42",
+			expected: []string{
+				fmt.Sprintf("%d 42 %v", TokenNumber, float64(42)),
+			},
+			hasError: false,
+		},
+		{
+			name:  "block comments",
+			input: "/* this is\na block\ncomment */42",
+			expected: []string{
+				fmt.Sprintf("%d 42 %v", TokenNumber, float64(42)),
+			},
+			hasError: false,
+		},
+		{
+			name:  "nested expressions with comments",
+			input: "(123 /* comment */ + /* another */ abc)",
+			expected: []string{
+				fmt.Sprintf("%d ( %v", TokenLeftParen, nil),
+				fmt.Sprintf("%d 123 %v", TokenNumber, float64(123)),
+				fmt.Sprintf("%d + %v", TokenPlus, nil),
+				fmt.Sprintf("%d abc %v", TokenIdentifier, nil),
+				fmt.Sprintf("%d ) %v", TokenRightParen, nil),
+			},
+			hasError: false,
+		},
+		{
+			name:     "unterminated block comment",
+			input:    "/* unterminated",
+			expected: []string{},
+			hasError: true,
+		},
+		{
+			name:     "block comment at end of input",
+			input:    "42 /* trailing */",
+			expected: []string{fmt.Sprintf("%d 42 %v", TokenNumber, 42.0)},
+			hasError: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errBuf bytes.Buffer
+			sc := NewScanner(tt.input, &errBuf)
+			tokens := sc.ScanTokens()
+
+			// Remove EOF token for comparison
+			tokens = tokens[:len(tokens)-1]
+
+			var result []string
+			for _, token := range tokens {
+				result = append(result, token.String())
+			}
+
+			if tt.hasError {
+				if errBuf.Len() == 0 {
+					t.Error("Expected error but got none")
+				}
+				return
+			}
+
+			if errBuf.Len() > 0 {
+				t.Errorf("Unexpected error: %s", errBuf.String())
+				return
+			}
+
+			if len(result) != len(tt.expected) {
+				t.Errorf("Token count mismatch.\nGot: %d tokens %v\nExpected: %d tokens %v",
+					len(result), result,
+					len(tt.expected), tt.expected)
+				return
+			}
+
+			for i := 0; i < len(result); i++ {
+				if tt.expected[i] != result[i] {
+					t.Errorf("Token mismatch at position %d:\nExpected: %q\nGot: %q",
+						i, tt.expected[i], result[i])
+				}
+			}
+		})
+	}
+}
+
+// Additional test for EOF token
+func TestTokenPositions(t *testing.T) {
+	input := "var a = 1;\n  print \"two\nlines\" /* a\ncomment */ + a;"
+	expected := []struct {
+		lexeme string
+		line   int
+		column int
+	}{
+		{"var", 1, 1}, {"a", 1, 5}, {"=", 1, 7}, {"1", 1, 9}, {";", 1, 10},
+		{"print", 2, 3}, {"\"two\nlines\"", 2, 9}, {"+", 4, 12}, {"a", 4, 14}, {";", 4, 15},
+		{"", 4, 16},
+	}
+
+	tokens := NewScanner(input, &bytes.Buffer{}).ScanTokens()
+	if len(tokens) != len(expected) {
+		t.Fatalf("Expected %d tokens, but got %d", len(expected), len(tokens))
+	}
+	for i, token := range tokens {
+		want := expected[i]
+		if token.Lexeme != want.lexeme || token.Line != want.line || token.Column != want.column {
+			t.Errorf("Token %d: expected %q at %d:%d, but got %q at %d:%d",
+				i, want.lexeme, want.line, want.column, token.Lexeme, token.Line, token.Column)
+		}
+	}
+}
+
+func TestEOFToken(t *testing.T) {
+	sc := NewScanner("", nil)
+	tokens := sc.ScanTokens()
+
+	if len(tokens) != 1 {
+		t.Errorf("Expected 1 token (EOF), got %d tokens", len(tokens))
+		return
+	}
+
+	if tokens[0].TokenType != TokenEof {
+		t.Errorf("Expected EOF token, got %v", tokens[0])
+	}
+}
+
+func TestParser(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		hasError bool
+	}{
+		// Simple literals
+		{"123", "Literal(123)", false},
+		{"\"hello\"", "Literal(hello)", false},
+
+		// Unary expressions
+		{"-123", "Unary(-, Literal(123))", false},
+		{"!true", "Unary(!, Literal(true))", false},
+
+		// Binary expressions
+		{"1 + 2", "Binary(Literal(1), +, Literal(2))", false},
+		{"3 * (4 - 5)", "Binary(Literal(3), *, Grouping(Binary(Literal(4), -, Literal(5))))", false},
+
+		// Comparison operators
+		{"4 > 3", "Binary(Literal(4), >, Literal(3))", false},
+		{"5 <= 6", "Binary(Literal(5), <=, Literal(6))", false},
+
+		// Equality operators
+		{"7 == 7", "Binary(Literal(7), ==, Literal(7))", false},
+		{"8 != 9", "Binary(Literal(8), !=, Literal(9))", false},
+
+		// Nested expressions
+		{"(1 + 2) * 3", "Binary(Grouping(Binary(Literal(1), +, Literal(2))), *, Literal(3))", false},
+
+		// Invalid expressions
+		{"(1 + )", "", true},  // Missing operand
+		{"5 + * 2", "", true}, // Invalid operator usage
+
+		// Additional valid expressions
+		{"1 + 2 * 3 - 4 / 5", "Binary(Binary(Literal(1), +, Binary(Literal(2), *, Literal(3))), -, Binary(Literal(4), /, Literal(5)))", false},
+		{"((1 + 2) * (3 - 4)) / 5", "Binary(Grouping(Binary(Grouping(Binary(Literal(1), +, Literal(2))), *, Grouping(Binary(Literal(3), -, Literal(4))))), /, Literal(5))", false},
+
+		// Additional invalid expressions
+		{"(1 + 2", "", true}, // Missing closing parenthesis
+		{"+", "", true},      // Lone operator
+		{"! + 1", "", true},  // Invalid unary operator usage
+
+		// Logical operators
+		{"true and false", "Logical(Literal(true), and, Literal(false))", false},
+		{"1 or 2 and 3", "Logical(Literal(1), or, Logical(Literal(2), and, Literal(3)))", false},
+		{"1 == 1 and 2 < 3", "Logical(Binary(Literal(1), ==, Literal(1)), and, Binary(Literal(2), <, Literal(3)))", false},
+		{"true and", "", true}, // Missing right operand
+
+		// Conditional operator
+		{"true ? 1 : 2", "Conditional(Literal(true), Literal(1), Literal(2))", false},
+		{"a ? b : c ? d : e", "Conditional(Variable(a), Variable(b), Conditional(Variable(c), Variable(d), Variable(e)))", false},
+		{"a or b ? 1 : 2", "Conditional(Logical(Variable(a), or, Variable(b)), Literal(1), Literal(2))", false},
+		{"a ? b ? 1 : 2 : 3", "Conditional(Variable(a), Conditional(Variable(b), Literal(1), Literal(2)), Literal(3))", false},
+		{"true ? 1", "", true},   // Missing ':'
+		{"true ? 1 2", "", true}, // Missing ':' before else branch
+		{"true ? : 2", "", true}, // Missing then branch
+
+		// Pipe operator desugars to calls
+		{"x |> f", "Call(Variable(f), Variable(x))", false},
+		{"x |> f(1, 2)", "Call(Variable(f), Variable(x), Literal(1), Literal(2))", false},
+		{"x |> f() |> g(y)", "Call(Variable(g), Call(Variable(f), Variable(x)), Variable(y))", false},
+		{"1 + 2 |> f() < 3", "Binary(Call(Variable(f), Binary(Literal(1), +, Literal(2))), <, Literal(3))", false},
+		{"x |> 1", "", true},       // Literal is not callable
+		{"x |> f() + 1", "", true}, // Right-hand side must be a call
+		{"x |>", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			// Setup scanner and parser
+			scanner := NewScanner(tt.input, nil)
+			tokens := scanner.ScanTokens()
+
+			parser := NewParser(tokens, &bytes.Buffer{})
+			expr, err := parser.Parse()
+
+			// Check for errors
+			if (err != nil) != tt.hasError {
+				t.Errorf("Unexpected error state. Got error: %v, Expected error: %v", err, tt.hasError)
+				return
+			}
+
+			if !tt.hasError {
+				// Convert expression to string for comparison
+				result := stringifyExpr(expr)
+				if result != tt.expected {
+					t.Errorf("Parsed expression does not match. Got: %s, Expected: %s", result, tt.expected)
+				}
+			}
+		})
+	}
+}
+
+// Helper function to stringify an expression for comparison
+func stringifyExpr(expr Expr) string {
+	switch e := expr.(type) {
+	case *Binary:
+		return fmt.Sprintf("Binary(%s, %s, %s)", stringifyExpr(e.Left), e.Operator.Lexeme, stringifyExpr(e.Right))
+	case *Grouping:
+		return fmt.Sprintf("Grouping(%s)", stringifyExpr(e.Expression))
+	case *Literal:
+		return fmt.Sprintf("Literal(%v)", e.Value)
+	case *Unary:
+		return fmt.Sprintf("Unary(%s, %s)", e.Operator.Lexeme, stringifyExpr(e.Right))
+	case *Logical:
+		return fmt.Sprintf("Logical(%s, %s, %s)", stringifyExpr(e.Left), e.Operator.Lexeme, stringifyExpr(e.Right))
+	case *Conditional:
+		return fmt.Sprintf("Conditional(%s, %s, %s)", stringifyExpr(e.Condition), stringifyExpr(e.ThenBranch), stringifyExpr(e.ElseBranch))
+	case *Variable:
+		return fmt.Sprintf("Variable(%s)", e.Name.Lexeme)
+	case *Call:
+		parts := []string{stringifyExpr(e.Callee)}
+		for _, arg := range e.Arguments {
+			parts = append(parts, stringifyExpr(arg))
+		}
+		return fmt.Sprintf("Call(%s)", strings.Join(parts, ", "))
+	default:
+		return "Unknown"
+	}
+}
+
+func TestParserErrorRecovery(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		errors     []string
+		statements int
+	}{
+		{"no errors", "var a = 1; print a;", nil, 2},
+		{"one error", "var = 1; print 2;", []string{
+			"[line 1] Error at '=': Expect variable name.",
+		}, 1},
+		{"errors on several lines", "var = 1;\nprint 2\nvar ok = 3;\nclass { }\nprint (1 + ;\nprint ok;", []string{
+			"[line 1] Error at '=': Expect variable name.",
+			"[line 3] Error at 'var': Expect ';' after value.",
+			"[line 4] Error at '{': Expect class name.",
+			"[line 5] Error at ';': Expect expression.",
+		}, 1},
+		{"error inside a statement", "if (true) print ;\nprint 1;", []string{
+			"[line 1] Error at ';': Expect expression.",
+		}, 1},
+		{"error at end", "print 1 +", []string{
+			"[line 1] Error at end: Expect expression.",
+		}, 0},
+		{"non-fatal error keeps parsing", "var a = 1 = 2; print a;", []string{
+			"[line 1] Error at '=': Invalid assignment target.",
+		}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var errBuf bytes.Buffer
+			tokens := NewScanner(tt.input, &errBuf).ScanTokens()
+			statements, err := NewParser(tokens, &errBuf).ParseStatements()
+
+			var messages []string
+			if err != nil {
+				parseErrors, ok := err.(ParseErrors)
+				if !ok {
+					t.Fatalf("Expected ParseErrors, but got %T: %v", err, err)
+				}
+				for _, parseError := range parseErrors {
+					messages = append(messages, parseError.Error())
+				}
+			}
+
+			if strings.Join(messages, "\n") != strings.Join(tt.errors, "\n") {
+				t.Errorf("Expected errors:\n%s\nbut got:\n%s", strings.Join(tt.errors, "\n"), strings.Join(messages, "\n"))
+			}
+			if reported := strings.TrimSpace(errBuf.String()); reported != strings.Join(tt.errors, "\n") {
+				t.Errorf("Expected every error to be reported, but got:\n%s", reported)
+			}
+			if len(statements) != tt.statements {
+				t.Errorf("Expected %d statements to survive, but got %d", tt.statements, len(statements))
+			}
+		})
+	}
+}
+
+func TestInterpreter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Literal values
+		{"123", "123", false},
+		{"\"hello\"", "hello", false},
+		{"nil", "nil", false},
+
+		// Unary expressions
+		{"-123", "-123", false},
+		{"!true", "false", false},
+		{"!nil", "true", false},
+		{"!123", "false", false},
+
+		// Binary expressions
+		{"1 + 2", "3", false},
+		{"5 - 3", "2", false},
+		{"2 * 3", "6", false},
+		{"8 / 4", "2", false},
+
+		// Operator precedence
+		{"1 + 2 * 3", "7", false},
+		{"(1 + 2) * 3", "9", false},
+
+		// Comparison operators
+		{"5 > 3", "true", false},
+		{"3 < 4", "true", false},
+		{"5 >= 5", "true", false},
+		{"3 <= 3", "true", false},
+
+		// Equality
+		{"4 == 4", "true", false},
+		{"4 != 5", "true", false},
+		{"nil == nil", "true", false},
+		{"nil != 0", "true", false},
+
+		// String concatenation
+		{"\"a\" + \"b\"", "ab", false},
+		{"\"hello\" + \" \" + \"world\"", "hello world", false},
+		{"\"a\" + \"\"", "a", false},
+
+		// Logical operators return the deciding operand
+		{"true and false", "false", false},
+		{"nil or \"default\"", "default", false},
+		{"1 and 2", "2", false},
+		{"nil and 1", "nil", false},
+		{"\"first\" or \"second\"", "first", false},
+		{"false or nil", "nil", false},
+		{"false and 1 / 0", "false", false}, // Right operand is never evaluated
+		{"true or 1 / 0", "true", false},
+
+		// Conditional operator
+		{"true ? 1 : 2", "1", false},
+		{"nil ? 1 : 2", "2", false},
+		{"false ? 1 : true ? 2 : 3", "2", false},
+		{"1 < 2 ? \"yes\" : \"no\"", "yes", false},
+		{"true ? 1 : 1 / 0", "1", false}, // Unchosen branch is never evaluated
+		{"false ? 1 / 0 : 2", "2", false},
+
+		// Invalid cases
+		{"1 + \"hello\"", "", true},
+		{"true + 1", "", true},
+		{"123 / \"string\"", "", true},
+		{"-nil", "", true},
+
+		// Division by zero
+		{"1 / 0", "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			scanner := NewScanner(tt.input, nil)
+			tokens := scanner.ScanTokens()
+
+			parser := NewParser(tokens, nil)
+			expr, err := parser.Parse()
+			if err != nil {
+				t.Errorf("Parsing failed: %v", err)
+				return
+			}
+
+			interpreter := NewInterpreter(nil, nil, nil)
+			var output string
+			var didError bool
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						didError = true
+					}
+				}()
+				output = stringify(interpreter.evaluate(expr))
+			}()
+
+			if tt.shouldError != didError {
+				t.Errorf("Expected error state %v, got %v", tt.shouldError, didError)
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestStatementsAndState(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Print statements
+		{"print 123;", "123\n", false},
+		{"print \"hello\";", "hello\n", false},
+		{"print nil;", "nil\n", false},
+
+		// Variable declarations
+		{"var a = 123; print a;", "123\n", false},
+		{"var b; print b;", "nil\n", false},
+		{"var c = \"test\"; print c;", "test\n", false},
+
+		// Variable reassignments
+		{"var x = 10; x = 20; print x;", "20\n", false},
+		{"var y; y = 50; print y;", "50\n", false},
+
+		// Expression statements
+		{"123;", "", false},
+		{"\"test\";", "", false},
+
+		// Undefined variables (should error, comment out to check error test cases)
+		{"print z;", "", true},
+		{"var x = 10; z = x + 1;", "", true},
+
+		// Nested expressions and scope
+		{"var a = 10; var b = a + 20; print b;", "30\n", false},
+		{"var outer = 10; { var inner = 20; print inner; } print outer;", "20\n10\n", false},
+		{"{ var a = 5; print a; } print a;", "", true}, // "a" is not defined outside the block
+
+		{"var x = 10; { var x = 20; print x; } print x;", "20\n10\n", false},                   // Nested block scoping
+		{"{ var a = 1; var b = 2; print a; print b; }", "1\n2\n", false},                       //  Multiple variable declarations in a block
+		{"var x = 10; { var x = x + 5; print x; }", "15\n", false},                             //  Shadowing variables
+		{"var a = 10; { a = a + 5; print a; }", "15\n", false},                                 //   Complex nested expressions and reassignments
+		{"var n = 3; var parity = n == 2 ? \"even\" : \"odd\"; print parity;", "odd\n", false}, // Conditional initializer
+		{"var a; a = false ? 1 : 2; print a;", "2\n", false},                                   // Conditional assignment value
+
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			var output string
+			var didError bool
+
+			// Capture output from the interpreter
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						didError = true
+					}
+				}()
+
+				var outBuf bytes.Buffer
+
+				// Run the interpreter
+				scanner := NewScanner(tt.input, nil)
+				tokens := scanner.ScanTokens()
+
+				parser := NewParser(tokens, nil)
+				statements, err := parser.ParseStatements()
+				if err != nil {
+					didError = true
+					return
+				}
+
+				interpreter := NewInterpreter(&outBuf, nil, nil)
+				if err := interpreter.InterpretStatements(statements); err != nil {
+					didError = true
+				}
+
+				output = outBuf.String()
+			}()
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestControlFlow(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// If-else statements
+		{"if (true) print 1; else print 2;", "1\n", false},
+		{"if (false) print 1; else print 2;", "2\n", false},
+		{"if (false) print 1;", "", false}, // No `else` branch
+
+		// Nested if-else
+		{"if (true) if (false) print 1; else print 2;", "2\n", false},
+
+		// While loops
+		{"var i = 0; while (i < 3) { print i; i = i + 1; }", "0\n1\n2\n", false},
+		{"var x = 5; while (x > 0) { print x; x = x - 1; }", "5\n4\n3\n2\n1\n", false},
+		{"while (false) print 1;", "", false}, // No loop iteration
+
+		// For loops
+		{"for (var i = 0; i < 3; i = i + 1) print i;", "0\n1\n2\n", false},
+		{"for (var x = 10; x > 5; x = x - 1) print x;", "10\n9\n8\n7\n6\n", false},
+		{"for (;;) { print \"infinite\"; break; }", "infinite\n", false}, // Infinite loop with `break`
+
+		// Complex nesting
+		{
+			"for (var i = 1; i <= 2; i = i + 1) { " +
+				"  for (var j = 1; j <= 2; j = j + 1) { " +
+				"    print i * j; " +
+				"  } " +
+				"}",
+			"1\n2\n2\n4\n", false,
+		},
+
+		{"var i = 0; while (true) { if (i == 3) break; print i; i = i + 1; }", "0\n1\n2\n", false},
+		{"for (var i = 0; i < 5; i = i + 1) { if (i == 2) break; print i; }", "0\n1\n", false},
+		{"while (false) { break; }", "", false},     // No iterations
+		{"for (;;) { break; print 1; }", "", false}, // Break immediately
+
+		// Continue
+		{"for (var i = 0; i < 5; i = i + 1) { if (i == 2) continue; print i; }", "0\n1\n3\n4\n", false},
+		{"var i = 0; while (i < 4) { i = i + 1; if (i == 2) continue; print i; }", "1\n3\n4\n", false},
+		{"for (var i = 0; i < 3; i = i + 1) { continue; print i; }", "", false}, // Increment still runs
+		{
+			"for (var i = 0; i < 2; i = i + 1) { " +
+				"  for (var j = 0; j < 3; j = j + 1) { " +
+				"    if (j == 1) continue; " +
+				"    print i * 10 + j; " +
+				"  } " +
+				"}",
+			"0\n2\n10\n12\n", false,
+		},
+
+		// Break only leaves the innermost loop
+		{
+			"for (var i = 0; i < 3; i = i + 1) { " +
+				"  for (var j = 0; j < 3; j = j + 1) { " +
+				"    if (j == 1) break; " +
+				"    print i * 10 + j; " +
+				"  } " +
+				"}",
+			"0\n10\n20\n", false,
+		},
+		{"var i = 0; while (true) { while (true) { break; } i = i + 1; if (i == 3) { break; } } print i;", "3\n", false},
+		{"var i = 0; while (i < 5) { i = i + 1; if (i > 1) { if (i == 3) { break; } } } print i;", "3\n", false},
+		{"for (var i = 0; i < 1000; i = i + 1) { if (i == 999) break; } print \"done\";", "done\n", false},
+
+		// Breaks inside closures
+		{
+			"for (var i = 0; i < 3; i = i + 1) { " +
+				"  fun f() { var n = 0; while (true) { n = n + 1; if (n > i) break; } return n; } " +
+				"  print f(); " +
+				"}",
+			"1\n2\n3\n", false,
+		},
+		{"var n = 0; while (n < 3) { n = n + 1; fun f() { break; } f(); } print n;", "", true}, // Break cannot escape a function
+
+		// Logical operators in conditions
+		{"if (true and false) print 1; else print 2;", "2\n", false},
+		{"if (false or true) print 1; else print 2;", "1\n", false},
+		{"var a = nil; var b = a or \"fallback\"; print b;", "fallback\n", false},
+		{"var i = 0; while (i < 10 and i != 2) { print i; i = i + 1; }", "0\n1\n", false},
+		{"var calls = 0; fun touch() { calls = calls + 1; return true; } false and touch(); true or touch(); print calls;", "0\n", false},
+
+		// Errors
+		{"if () print 1;", "", true},    // Missing condition
+		{"while () print 1;", "", true}, // Missing condition
+		{"break; ", "", true},           //break outside a loop
+
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			var output string
+			var didError bool
+
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						didError = true
+					}
+				}()
+
+				var outBuf bytes.Buffer
+
+				// Run the interpreter
+				scanner := NewScanner(tt.input, nil)
+				tokens := scanner.ScanTokens()
+
+				parser := NewParser(tokens, nil)
+				statements, err := parser.ParseStatements()
+				if err != nil {
+					didError = true
+					return
+				}
+
+				interpreter := NewInterpreter(&outBuf, nil, nil)
+				if err := interpreter.InterpretStatements(statements); err != nil {
+					didError = true
+				}
+
+				output = outBuf.String()
+			}()
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestFunctions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Simple function declaration and call
+		{
+			"fun sayHi() { print \"Hi!\"; } sayHi();",
+			"Hi!\n",
+			false,
+		},
+		// Function with parameters
+		{
+			"fun greet(name) { print \"Hello, \" + name + \"!\"; } greet(\"Alice\");",
+			"Hello, Alice!\n",
+			false,
+		},
+		// Returning a value
+		{
+			"fun add(a, b) { return a + b; } print add(3, 4);",
+			"7\n",
+			false,
+		},
+		// Nested function calls
+		{
+			"fun square(x) { return x * x; } fun sumOfSquares(a, b) { return square(a) + square(b); } print sumOfSquares(3, 4);",
+			"25\n",
+			false,
+		},
+		// Function without return
+		{
+			"fun noReturn() { 123; } print noReturn();",
+			"nil\n",
+			false,
+		},
+		// Function with no arguments
+		{
+			"fun doSomething() { return 42; } print doSomething();",
+			"42\n",
+			false,
+		},
+		// Recursion
+		{
+			`
+            fun factorial(n) {
+              if (n <= 1) return 1;
+              return n * factorial(n - 1);
+            }
+            print factorial(5); // 120
+            `,
+			"120\n",
+			false,
+		},
+		// Function shadowing
+		{
+			`
+            fun outer() {
+              fun inner() {
+                return "inner";
+              }
+              return inner();
+            }
+            print outer(); // "inner"
+            `,
+			"inner\n",
+			false,
+		},
+		// Pipe operator
+		{
+			"fun double(x) { return x * 2; } fun add(a, b) { return a + b; } print 5 |> double |> add(1);",
+			"11\n",
+			false,
+		},
+		{
+			"fun greet(name, greeting) { return greeting + \", \" + name; } print \"Ann\" |> greet(\"Hi\");",
+			"Hi, Ann\n",
+			false,
+		},
+		{
+			"class Box { init(v) { this.v = v; } } print (3 |> Box).v;",
+			"3\n",
+			false,
+		},
+		{
+			"var notAFunction = 1; 2 |> notAFunction;",
+			"",
+			true,
+		},
+		{
+			"fun one(a) { return a; } 1 |> one(2);",
+			"",
+			true,
+		},
+		// Calling a function with the wrong number of arguments
+		{
+			"fun oneArg(x) { print x; } oneArg();",
+			"",
+			true,
+		},
+		{
+			"fun oneArg(x) { print x; } oneArg(1, 2);",
+			"",
+			true,
+		},
+
+		// Error: Undefined function
+		{
+			"undefinedFunction();",
+			"",
+			true,
+		},
+		// More than 255 arguments
+		{
+			"fun tooManyArgs(" + strings.Repeat("x,", 255) + "x) { print x; }",
+			"",
+			true,
+		},
+		// More than 255 parameters
+		{
+			"fun tooManyParams(" + strings.Repeat("x,", 255) + "x) { return 42; }",
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			var output string
+			var didError bool
+
+			// Capture output from the interpreter
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						didError = true
+					}
+				}()
+
+				var outBuf bytes.Buffer
+
+				// Run the interpreter
+				scanner := NewScanner(tt.input, nil)
+				tokens := scanner.ScanTokens()
+
+				parser := NewParser(tokens, nil)
+				statements, err := parser.ParseStatements()
+				if err != nil {
+					didError = true
+					return
+				}
+
+				interpreter := NewInterpreter(&outBuf, nil, nil)
+				if err := interpreter.InterpretStatements(statements); err != nil {
+					didError = true
+				}
+
+				output = outBuf.String()
+			}()
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestResolver(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Basic variable declaration
+		{
+			`var a = 1; print a;`,
+			"1\n",
+			false,
+		},
+		// Block scope variable shadowing
+		{
+			`var a = 1; { var a = 2; print a; } print a;`,
+			"2\n1\n",
+			false,
+		},
+		// Undefined variable access
+		{
+			`print b;`,
+			"",
+			true,
+		},
+		// Function declaration and usage
+		{
+			`fun test() { print "Hello, World!"; } test();`,
+			"Hello, World!\n",
+			false,
+		},
+		// Return from function
+		{
+			`fun test() { return 42; } print test();`,
+			"42\n",
+			false,
+		},
+		// Return outside function
+		{
+			`return 42;`,
+			"",
+			true,
+		},
+		// Loop control inside loops
+		{
+			`for (var i = 0; i < 3; i = i + 1) { if (i == 1) continue; print i; }`,
+			"0\n2\n",
+			false,
+		},
+		// Loop depth is tracked per function
+		{
+			`while (true) { fun f() { break; } f(); }`,
+			"",
+			true,
+		},
+		{
+			`fun f() { for (;;) { fun g() { continue; } } }`,
+			"",
+			true,
+		},
+		{
+			`for (;;) { fun f() { while (true) { break; } return 1; } print f(); break; }`,
+			"1\n",
+			false,
+		},
+		// Break outside loop
+		{
+			`break;`,
+			"",
+			true,
+		},
+		// Continue outside loop
+		{
+			`if (true) { continue; }`,
+			"",
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			var output string
+			var didError bool
+
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						didError = true
+					}
+				}()
+
+				var outBuf bytes.Buffer
+
+				// Run the resolver and interpreter
+				scanner := NewScanner(tt.input, nil)
+				tokens := scanner.ScanTokens()
+
+				parser := NewParser(tokens, nil)
+				statements, err := parser.ParseStatements()
+				if err != nil {
+					didError = true
+					return
+				}
+
+				interpreter := NewInterpreter(&outBuf, nil, nil)
+				resolver := NewResolver(interpreter)
+
+				resolver.Resolve(statements)
+				if len(resolver.Errors()) > 0 {
+					didError = true
+					return
+				}
+				if err := interpreter.InterpretStatements(statements); err != nil {
+					didError = true
+				}
+
+				output = outBuf.String()
+			}()
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestResolverErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		errors []string
+	}{
+		{"valid program", "fun f(a) { var b = a; return b; }", nil},
+		{"top-level return", "return 1;", []string{
+			"[line 1, column 1] Error at 'return': Cannot return from top-level code.",
+		}},
+		{"redeclared local", "{\n  var a = 1;\n  var a = 2;\n}", []string{
+			"[line 3, column 7] Error at 'a': Variable with name 'a' already declared in this scope.",
+		}},
+		{"own initializer", "{ var b = b; }", []string{
+			"[line 1, column 11] Error at 'b': Cannot read local variable 'b' in its own initializer.",
+		}},
+		{"this outside class", "print this;", []string{
+			"[line 1, column 7] Error at 'this': Cannot use 'this' outside of a class.",
+		}},
+		{"super outside class", "super.m();", []string{
+			"[line 1, column 1] Error at 'super': Cannot use 'super' outside of a class.",
+		}},
+		{"super without superclass", "class A { m() { super.m(); } }", []string{
+			"[line 1, column 17] Error at 'super': Cannot use 'super' in a class with no superclass.",
+		}},
+		{"value returned from initializer", "class Foo { init() { return 1; } }", []string{
+			"[line 1, column 22] Error at 'return': Cannot return a value from an initializer.",
+		}},
+		{"every error is reported", "break;\nfun f() { continue; }\nreturn;", []string{
+			"[line 1, column 1] Error at 'break': Cannot use 'break' outside of a loop.",
+			"[line 2, column 11] Error at 'continue': Cannot use 'continue' outside of a loop.",
+			"[line 3, column 1] Error at 'return': Cannot return from top-level code.",
+		}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tokens := NewScanner(tt.input, &bytes.Buffer{}).ScanTokens()
+			statements, err := NewParser(tokens, &bytes.Buffer{}).ParseStatements()
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+
+			resolver := NewResolver(NewInterpreter(nil, nil, nil))
+			resolver.Resolve(statements)
+
+			var messages []string
+			for _, resolveError := range resolver.Errors() {
+				messages = append(messages, resolveError.Error())
+			}
+			if strings.Join(messages, "\n") != strings.Join(tt.errors, "\n") {
+				t.Errorf("Expected errors:\n%s\nbut got:\n%s", strings.Join(tt.errors, "\n"), strings.Join(messages, "\n"))
+			}
+		})
+	}
+}
+
+func TestClassesAndInheritance(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Class declaration and instantiation
+		{
+			`class Breakfast { cook() { print "Eggs a-fryin'!"; } } Breakfast().cook();`,
+			"Eggs a-fryin'!\n",
+			false,
+		},
+		// Class initializer
+		{
+			`class Foo { init() { print "Foo initialized!"; } } Foo();`,
+			"Foo initialized!\n",
+			false,
+		},
+		// Inheritance
+		{
+			`class Animal { speak() { print "The animal makes a sound."; } } class Dog < Animal { speak() { print "The dog barks."; } } Dog().speak();`,
+			"The dog barks.\n",
+			false,
+		},
+		// Super call
+		{
+			`class A { method() { print "A method"; } } class B < A { method() { print "B method"; super.method(); } } B().method();`,
+			"B method\nA method\n",
+			false,
+		},
+		// Field assignment
+		{
+			`class Point {} var p = Point(); p.x = 3; p.x = p.x + 1; print p.x;`,
+			"4\n",
+			false,
+		},
+		// Property assignment through a chain of gets, used as an expression
+		{
+			`class Node {} var a = Node(); a.next = Node(); print a.next.value = "set"; print a.next.value;`,
+			"set\nset\n",
+			false,
+		},
+		// Assigning to a call is still invalid
+		{
+			`class Point {} Point() = 1;`,
+			"",
+			true,
+		},
+		// 'this' outside class
+		{
+			`print this;`,
+			"",
+			true,
+		},
+		// 'super' outside class
+		{
+			`print super.method();`,
+			"",
+			true,
+		},
+		// Inheritance from non-class
+		{
+			`var NotAClass = "not a class"; class Subclass < NotAClass {}`,
+			"",
+			true,
+		},
+		// Calling init directly returns the instance
+		{
+			`class Foo { init() { this.n = 1; } } var foo = Foo(); print foo.init() == foo;`,
+			"true\n",
+			false,
+		},
+		// init can be invoked again to reinitialize
+		{
+			`class Counter { init(n) { this.n = n; } } var c = Counter(1); c.init(5); print c.n;`,
+			"5\n",
+			false,
+		},
+		// A bound init remembers its instance
+		{
+			`class Foo { init() {} } var foo = Foo(); var init = foo.init; print init() == foo;`,
+			"true\n",
+			false,
+		},
+		// Early bare return in init still yields this
+		{
+			`class Foo { init(skip) { this.a = 1; if (skip) return; this.a = 2; } } print Foo(true).a; print Foo(true).init(false).a;`,
+			"1\n2\n",
+			false,
+		},
+		// Inherited init returns the subclass instance
+		{
+			`class A { init() { this.x = 1; } } class B < A {} var b = B(); print b.init() == b;`,
+			"true\n",
+			false,
+		},
+		// Returning a value from init is a resolve error
+		{
+			`class Foo { init() { return 1; } }`,
+			"",
+			true,
+		},
+		// Functions nested in init may return values
+		{
+			`class Foo { init() { fun f() { return 1; } this.x = f(); } } print Foo().x;`,
+			"1\n",
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			var output string
+			var didError bool
+
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						didError = true
+					}
+				}()
+
+				var outBuf bytes.Buffer
+
+				scanner := NewScanner(tt.input, nil)
+				tokens := scanner.ScanTokens()
+
+				parser := NewParser(tokens, nil)
+				statements, err := parser.ParseStatements()
+				if err != nil {
+					didError = true
+					return
+				}
+
+				interpreter := NewInterpreter(&outBuf, nil, nil)
+				resolver := NewResolver(interpreter)
+
+				resolver.Resolve(statements)
+				if len(resolver.Errors()) > 0 {
+					didError = true
+					return
+				}
+				if err := interpreter.InterpretStatements(statements); err != nil {
+					didError = true
+				}
+
+				output = outBuf.String()
+			}()
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+// runProgram scans, parses, resolves and interprets input, returning what it
+// printed and whether any stage failed.
+func runProgram(input string) (output string, didError bool) {
+	var outBuf bytes.Buffer
+
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				didError = true
+			}
+		}()
+
+		scanner := NewScanner(input, nil)
+		tokens := scanner.ScanTokens()
+
+		parser := NewParser(tokens, nil)
+		statements, err := parser.ParseStatements()
+		if err != nil {
+			didError = true
+			return
+		}
+
+		interpreter := NewInterpreter(&outBuf, nil, nil)
+		resolver := NewResolver(interpreter)
+
+		resolver.Resolve(statements)
+		if len(resolver.Errors()) > 0 {
+			didError = true
+			return
+		}
+		if typeErrors := NewTypeChecker().Check(statements); len(typeErrors) > 0 {
+			didError = true
+			return
+		}
+		if err := interpreter.InterpretStatements(statements); err != nil {
+			didError = true
+		}
+	}()
+
+	return outBuf.String(), didError
+}
+
+func TestValuePrinting(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"print 1000000;", "1000000\n"},
+		{"print 1.0;", "1\n"},
+		{"print 2.5;", "2.5\n"},
+		{"print -0.125;", "-0.125\n"},
+		{"print 0.1 + 0.2;", "0.30000000000000004\n"},
+		{"print 123456789012345678901234.0;", "1.2345678901234569e+23\n"},
+		{"print [1000000, {\"n\": 3.0}];", "[1000000, {n: 3}]\n"},
+		{"fun add(a, b) {} print add;", "<fn add>\n"},
+		{"print () => nil;", "<fn anonymous>\n"},
+		{"print {}.keys;", "<native fn>\n"},
+		{"class Point {} print Point;", "Point\n"},
+		{"class Point {} print Point();", "Point instance\n"},
+		{"class Point { init(x, y) { this.x = x; this.y = y; } toString() { return \"(\" + this.x + \", \" + this.y + \")\"; } } print Point(\"1\", \"2\");", "(1, 2)\n"},
+		{"class A { toString() { return \"an A\"; } } class B < A {} print [B(), {\"b\": B()}];", "[an A, {b: an A}]\n"},
+		{"class A { toString() { return 1; } } print A();", "A instance\n"},
+		{"class A { toString(x) { return x; } } print A();", "A instance\n"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			output, didError := runProgram(tt.input)
+			if didError {
+				t.Fatalf("Unexpected error")
+			}
+			if output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestNativeFunctions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		{`print clock;`, "<native fn>\n", false},
+		{`var t = clock(); print type(t); print t > 0;`, "number\ntrue\n", false},
+		{`print type(nil); print type(true); print type(1); print type("s");`, "nil\nboolean\nnumber\nstring\n", false},
+		{`class A {} fun f() {} print type([]); print type({}); print type(A); print type(A()); print type(f); print type(clock);`,
+			"list\nmap\nclass\ninstance\nfunction\nfunction\n", false},
+		{`var type = 1; print type;`, "1\n", false},
+		{`type Num = number; var n: Num = 1; print type(n);`, "number\n", false},
+		{`print str(1000000) + "!"; print str([1, nil]);`, "1000000!\n[1, nil]\n", false},
+		{`class P { toString() { return "a P"; } } print str(P()) + ".";`, "a P.\n", false},
+		{`print num("42") + 1; print num(" 2.5 "); print num(7);`, "43\n2.5\n7\n", false},
+		{`try { num("abc"); } catch (e) { print e.message; }`, "Cannot convert 'abc' to a number.\n", false},
+		{`print len("héllo"); print len([1, 2]); print len({"a": 1});`, "5\n2\n1\n", false},
+		{`try { len(1); } catch (e) { print e.message; }`, "Cannot take the length of number.\n", false},
+		{`len();`, "", true},
+		{`try { input(1, 2); } catch (e) { print e.message; }`, "Expected at most 1 argument but got 2.\n", false},
+		{`var clock = 1; print clock;`, "1\n", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			output, didError := runProgram(tt.input)
+			if didError != tt.shouldError {
+				t.Fatalf("Expected error: %v, but got: %v", tt.shouldError, didError)
+			}
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestInput(t *testing.T) {
+	t.Parallel()
+	var outBuf bytes.Buffer
+	interpreter := New(Options{Stdout: &outBuf, Stderr: io.Discard, Stdin: strings.NewReader("Ada\r\nLovelace")})
+	err := interpreter.Run(`var name = input("Name? "); print "Hello, " + name; print input(); print input();`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if expected := "Name? Hello, Ada\nLovelace\nnil\n"; outBuf.String() != expected {
+		t.Errorf("Expected output %q, but got %q", expected, outBuf.String())
+	}
+
+	outBuf.Reset()
+	interpreter = NewInterpreter(&outBuf, nil, nil)
+	if err := interpreter.Run(`print input();`); err != nil || outBuf.String() != "nil\n" {
+		t.Errorf("Expected input() without a reader to return nil, but got %q (error %v)", outBuf.String(), err)
+	}
+}
+
+func TestDefineNative(t *testing.T) {
+	interpreter := NewInterpreter(nil, nil, nil)
+	interpreter.DefineNative("sum", Variadic, func(arguments []interface{}) (interface{}, error) {
+		total := 0.0
+		for _, argument := range arguments {
+			number, ok := argument.(float64)
+			if !ok {
+				return nil, fmt.Errorf("sum() takes numbers.")
+			}
+			total += number
+		}
+		return total, nil
+	})
+
+	tokens := NewScanner(`sum(1, 2, 3) + sum()`, nil).ScanTokens()
+	expr, _ := NewParser(tokens, nil).Parse()
+	if value, err := interpreter.Evaluate(expr); err != nil || value != 6.0 {
+		t.Errorf("Expected 6, but got %v (error %v)", value, err)
+	}
+
+	tokens = NewScanner("\nsum(1, \"two\")", nil).ScanTokens()
+	expr, _ = NewParser(tokens, nil).Parse()
+	_, err := interpreter.Evaluate(expr)
+	runtimeError, ok := err.(*LoxRuntimeError)
+	if !ok {
+		t.Fatalf("Expected a *LoxRuntimeError, but got %T: %v", err, err)
+	}
+	if runtimeError.Message != "sum() takes numbers." || runtimeError.Token.Line != 2 {
+		t.Errorf("Expected 'sum() takes numbers.' on line 2, but got %q on line %d", runtimeError.Message, runtimeError.Token.Line)
+	}
+}
+
+func TestEmbeddingAPI(t *testing.T) {
+	var outBuf, errBuf bytes.Buffer
+	interpreter := New(Options{Stdout: &outBuf, Stderr: &errBuf})
+	interpreter.Define("limit", 10.0)
+
+	if err := interpreter.Run(`var doubled = limit * 2; print doubled;`); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if outBuf.String() != "20\n" {
+		t.Errorf("Expected output %q, but got %q", "20\n", outBuf.String())
+	}
+
+	// Definitions persist from one call to the next.
+	if value, err := interpreter.Eval("doubled + limit"); err != nil || value != 30.0 {
+		t.Errorf("Expected 30, but got %v (error %v)", value, err)
+	}
+
+	tests := []struct {
+		name  string
+		eval  string
+		run   string
+		check func(error) bool
+	}{
+		{"scan error", "", "var s = \"open;", func(err error) bool { _, ok := err.(ScanErrors); return ok }},
+		{"parse error", "", "var = 1;", func(err error) bool { _, ok := err.(ParseErrors); return ok }},
+		{"resolve error", "", "return 1;", func(err error) bool { _, ok := err.(ResolveErrors); return ok }},
+		{"type error", "", "var n: number = \"one\";", func(err error) bool { _, ok := err.(TypeErrors); return ok }},
+		{"runtime error", "", "print 1 / 0;", func(err error) bool { _, ok := err.(*LoxRuntimeError); return ok }},
+		{"trailing tokens in expression", "1 2", "", func(err error) bool { _, ok := err.(ParseErrors); return ok }},
+		{"undefined variable in expression", "missing", "", func(err error) bool { _, ok := err.(*LoxRuntimeError); return ok }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errBuf.Reset()
+			var err error
+			if tt.eval != "" {
+				_, err = interpreter.Eval(tt.eval)
+			} else {
+				err = interpreter.Run(tt.run)
+			}
+			if !tt.check(err) {
+				t.Errorf("Unexpected error %T: %v", err, err)
+			}
+			if !strings.Contains(errBuf.String(), "error") {
+				t.Errorf("Expected the error to be reported, but got %q", errBuf.String())
+			}
+		})
+	}
+}
+
+func TestCallFromGo(t *testing.T) {
+	t.Parallel()
+	var outBuf, errBuf bytes.Buffer
+	interpreter := New(Options{Stdout: &outBuf, Stderr: &errBuf})
+	err := interpreter.Run(`
+fun handler(request) {
+  return {"path": request["path"], "size": len(request["body"]), "ok": true};
+}
+fun total(numbers) {
+  var sum = 0;
+  for (var i = 0; i < len(numbers); i = i + 1) sum = sum + numbers[i];
+  return sum;
+}
+fun fail() { return 1 / 0; }
+class Counter {
+  init(start) { this.count = start; }
+  add(n) { this.count = this.count + n; return this.count; }
+}
+var greeting = "hi";
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	result, err := interpreter.Call("handler", map[string]interface{}{"path": "/", "body": []int{1, 2, 3}})
+	expected := map[string]interface{}{"path": "/", "size": 3.0, "ok": true}
+	if err != nil || fmt.Sprint(result) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, but got %v (error %v)", expected, result, err)
+	}
+	if result, err := interpreter.Call("total", []float32{1.5, 2.5}); err != nil || result != 4.0 {
+		t.Errorf("Expected 4, but got %v (error %v)", result, err)
+	}
+	if result, err := interpreter.Call("str", int64(7)); err != nil || result != "7" {
+		t.Errorf("Expected \"7\", but got %v (error %v)", result, err)
+	}
+
+	counter, err := interpreter.Call("Counter", 10)
+	instance, ok := counter.(*LoxInstance)
+	if err != nil || !ok {
+		t.Fatalf("Expected an instance, but got %T (error %v)", counter, err)
+	}
+	interpreter.CallMethod(instance, "add", 5)
+	if result, err := interpreter.CallMethod(instance, "add", uint8(1)); err != nil || result != 16.0 {
+		t.Errorf("Expected 16, but got %v (error %v)", result, err)
+	}
+
+	if value, found := interpreter.Global("greeting"); !found || value != "hi" {
+		t.Errorf("Expected greeting to be \"hi\", but got %v (found %v)", value, found)
+	}
+	if _, found := interpreter.Global("missing"); found {
+		t.Errorf("Expected missing to be undefined")
+	}
+
+	errorTests := []struct {
+		name    string
+		call    func() (Value, error)
+		message string
+	}{
+		{"undefined function", func() (Value, error) { return interpreter.Call("handlr", nil) },
+			"Undefined variable 'handlr'. Did you mean 'handler'?"},
+		{"not callable", func() (Value, error) { return interpreter.Call("greeting") },
+			"'greeting' is not a function or class."},
+		{"wrong arity", func() (Value, error) { return interpreter.Call("total") },
+			"Expected 1 arguments but got 0."},
+		{"unconvertible argument", func() (Value, error) { return interpreter.Call("total", struct{}{}) },
+			"Argument 1 to 'total': Cannot convert struct {} to a Lox value."},
+		{"runtime error", func() (Value, error) { return interpreter.Call("fail") },
+			"Division by zero.\n[line 10, column 23]"},
+		{"native error", func() (Value, error) { return interpreter.Call("num", "abc") },
+			"Cannot convert 'abc' to a number."},
+		{"undefined method", func() (Value, error) { return interpreter.CallMethod(instance, "ad", 1) },
+			"Undefined property 'ad'. Did you mean 'add'?"},
+		{"uncaught exception", func() (Value, error) {
+			interpreter.Run(`fun raise() { throw "boom"; }`)
+			return interpreter.Call("raise")
+		}, "Uncaught exception: boom\n[line 1, column 15]"},
+	}
+
+	for _, tt := range errorTests {
+		t.Run(tt.name, func(t *testing.T) {
+			errBuf.Reset()
+			_, err := tt.call()
+			if err == nil || err.Error() != tt.message {
+				t.Errorf("Expected error %q, but got %v", tt.message, err)
+			}
+			// Every error is typed and reported, whether or not the call began.
+			var runtimeError *LoxRuntimeError
+			if !errors.As(err, &runtimeError) {
+				t.Errorf("Expected a *LoxRuntimeError, but got %T", err)
+			}
+			reported := "runtime error: " + strings.SplitN(tt.message, "\n", 2)[0]
+			if !strings.HasPrefix(errBuf.String(), reported) {
+				t.Errorf("Expected %q to be reported, but got %q", reported, errBuf.String())
+			}
+		})
+	}
+}
+
+func TestValueConversion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"nil", nil, "nil"},
+		{"int", 42, "42"},
+		{"float32", float32(0.5), "0.5"},
+		{"bool", true, "true"},
+		{"string", "hi", "hi"},
+		{"slice", []interface{}{1, "two", []string{"three"}}, "[1, two, [three]]"},
+		{"array", [2]bool{true, false}, "[true, false]"},
+		{"map in key order", map[string]int{"b": 2, "a": 1}, "{a: 1, b: 2}"},
+		{"map with number keys", map[int]string{2: "two", 1: "one"}, "{1: one, 2: two}"},
+		{"nil slice", []int(nil), "nil"},
+		{"nil pointer", (*int)(nil), "nil"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			value, err := toLox(tt.value)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if output := stringify(value); output != tt.expected {
+				t.Errorf("Expected %q, but got %q", tt.expected, output)
+			}
+		})
+	}
+
+	// Values round-trip, and a list that contains itself converts.
+	interpreter := NewInterpreter(nil, nil, nil)
+	interpreter.Run(`var m = {"list": [1, true, nil], 1: "one"}; var l = [1]; l[0] = l;`)
+	m, _ := interpreter.Global("m")
+	if expected := map[interface{}]interface{}{"list": []interface{}{1.0, true, nil}, 1.0: "one"}; fmt.Sprint(m) != fmt.Sprint(expected) {
+		t.Errorf("Expected %v, but got %v", expected, m)
+	}
+	l, _ := interpreter.Global("l")
+	if list, ok := l.([]interface{}); !ok || len(list) != 1 {
+		t.Errorf("Expected a one-element list, but got %T", l)
+	}
+
+	if _, err := toLox(map[float64]int{math.NaN(): 1}); err == nil {
+		t.Errorf("Expected an error for a NaN key")
+	}
+	if err := interpreter.Define("f", func() {}); err == nil {
+		t.Errorf("Expected an error defining a Go func")
+	}
+}
+
+func TestLists(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Literals and printing
+		{`print [1, 2, 3];`, "[1, 2, 3]\n", false},
+		{`print [];`, "[]\n", false},
+		{`print [1, [2, [3]], "four", nil, true];`, "[1, [2, [3]], four, nil, true]\n", false},
+
+		// Indexing
+		{`var xs = [10, 20, 30]; print xs[0]; print xs[2];`, "10\n30\n", false},
+		{`var xs = [[1, 2], [3, 4]]; print xs[1][0];`, "3\n", false},
+		{`fun make() { return [5, 6]; } print make()[1];`, "6\n", false},
+		{`var xs = [1, 2]; var i = 0; print xs[i + 1];`, "2\n", false},
+
+		// Index assignment
+		{`var xs = [1, 2, 3]; xs[1] = "two"; print xs;`, "[1, two, 3]\n", false},
+		{`var xs = [[0]]; xs[0][0] = xs[0][0] + 1; print xs;`, "[[1]]\n", false},
+		{`var xs = [0]; print xs[0] = 5;`, "5\n", false},
+		{`var a = [1]; var b = a; b[0] = 2; print a;`, "[2]\n", false},
+
+		// Lists in closures and loops
+		{`var xs = [0, 0, 0]; for (var i = 0; i < 3; i = i + 1) { xs[i] = i * i; } print xs;`, "[0, 1, 4]\n", false},
+
+		// Errors
+		{`var xs = [1, 2]; print xs[2];`, "", true},
+		{`var xs = [1, 2]; print xs[-1];`, "", true},
+		{`var xs = [1, 2]; xs[5] = 1;`, "", true},
+		{`var xs = [1, 2]; print xs[0.5];`, "", true},
+		{`var xs = [1, 2]; print xs["0"];`, "", true},
+		{`var n = 1; print n[0];`, "", true},
+		{`print [1, 2;`, "", true},
+		{`var xs = [1]; print xs[0;`, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			output, didError := runProgram(tt.input)
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestMaps(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Literals and printing in insertion order
+		{`print {"b": 1, "a": 2};`, "{b: 1, a: 2}\n", false},
+		{`print {};`, "{}\n", false},
+		{`print {1: "one", true: "yes", nil: "none"};`, "{1: one, true: yes, nil: none}\n", false},
+		{`print {"xs": [1, 2], "m": {"k": "v"}};`, "{xs: [1, 2], m: {k: v}}\n", false},
+
+		// Indexing and assignment
+		{`var m = {"a": 1}; print m["a"];`, "1\n", false},
+		{`var m = {}; m["x"] = 10; m["x"] = m["x"] + 1; print m["x"];`, "11\n", false},
+		{`var m = {"a": 1, "b": 2}; m["a"] = 3; m["c"] = 4; print m;`, "{a: 3, b: 2, c: 4}\n", false},
+		{`var m = {1: "int"}; print m[2 - 1];`, "int\n", false},
+		{`var m = {"a": 1, "a": 2}; print m;`, "{a: 2}\n", false},
+
+		// Membership and deletion
+		{`var m = {"a": nil}; print m.has("a"); print m.has("b");`, "true\nfalse\n", false},
+		{`var m = {"a": 1, "b": 2, "c": 3}; print m.remove("b"); print m; print m.remove("b");`, "2\n{a: 1, c: 3}\nnil\n", false},
+		{`var m = {"a": 1, "b": 2}; m.remove("a"); m["a"] = 3; print m.keys(); print m.values();`, "[b, a]\n[2, 3]\n", false},
+
+		// Blocks are still blocks at statement start
+		{`{ print "block"; }`, "block\n", false},
+		{`{}`, "", false},
+
+		// Errors
+		{`var m = {"a": 1}; print m["b"];`, "", true},
+		{`var m = {}; m[[1]] = 1;`, "", true},
+		{`print {[]: 1};`, "", true},
+		{`var m = {}; print m.size();`, "", true},
+		{`print {"a" 1};`, "", true},
+		{`print {"a": 1;`, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			output, didError := runProgram(tt.input)
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestLambdas(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Anonymous function expressions
+		{`var add = fun (a, b) { return a + b; }; print add(1, 2);`, "3\n", false},
+		{`print fun () { return "now"; }();`, "now\n", false},
+		{`fun apply(f, x) { return f(x); } print apply(fun (n) { return n * n; }, 4);`, "16\n", false},
+		{`fun (a) { print a; }(7);`, "7\n", false}, // Expression statement starting with 'fun'
+
+		// Arrow functions
+		{`var double = (a) => a * 2; print double(21);`, "42\n", false},
+		{`var zero = () => 0; print zero();`, "0\n", false},
+		{`var sum = (a, b) => { var s = a + b; return s; }; print sum(2, 3);`, "5\n", false},
+		{`print 5 |> (x) => x + 1;`, "6\n", false},
+		{`var curry = (a) => (b) => a + b; print curry(1)(2);`, "3\n", false},
+		{`print (1 + 2) * 3;`, "9\n", false}, // Groupings are not arrow functions
+
+		// Closures capture the enclosing environment
+		{
+			`fun counter() { var n = 0; return fun () { n = n + 1; return n; }; } var c = counter(); c(); print c();`,
+			"2\n",
+			false,
+		},
+		{
+			`var xs = [nil, nil]; for (var i = 0; i < 2; i = i + 1) { var j = i; xs[i] = () => j; } print xs[0]() + xs[1]();`,
+			"1\n",
+			false,
+		},
+		{`var a = "global"; { var a = "local"; var f = () => a; print f(); }`, "local\n", false},
+
+		// Parenthesized expressions are not mistaken for parameter lists
+		{`var x = 2; print (x) * 3;`, "6\n", false},
+		{`var x = 2; print (x + 1) * 2;`, "6\n", false},
+
+		// Printing
+		{`print fun () {};`, "<fn anonymous>\n", false},
+		{`print (x) => x;`, "<fn anonymous>\n", false},
+		{`fun named() {} print named;`, "<fn named>\n", false},
+
+		// Errors
+		{`var f = (a) => a; f();`, "", true},
+		{`var f = fun (a { return a; };`, "", true},
+		{`var f = (a, 1) => a;`, "", true},
+		{`var f = () => ;`, "", true},
+		{`var f = fun () { return a; }; f();`, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			output, didError := runProgram(tt.input)
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestExceptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Throwing and catching values
+		{`try { throw "boom"; } catch (e) { print e; }`, "boom\n", false},
+		{`try { throw [1, 2]; } catch (e) { print e[1]; }`, "2\n", false},
+		{`try { print "before"; throw 1; print "after"; } catch (e) { print "caught"; }`, "before\ncaught\n", false},
+		{`try { print "ok"; } catch (e) { print "unreachable"; }`, "ok\n", false},
+		{`fun fail() { throw "deep"; } fun outer() { fail(); print "skipped"; } try { outer(); } catch (e) { print e; }`, "deep\n", false},
+		{`try { try { throw 1; } catch (e) { throw e + 1; } } catch (e) { print e; }`, "2\n", false},
+		{`var e = "outer"; try { throw "inner"; } catch (e) { print e; } print e;`, "inner\nouter\n", false},
+
+		// Runtime errors become error objects
+		{`try { 1 / 0; } catch (e) { print e.message; }`, "Division by zero.\n", false},
+		{`try { undefinedVariable; } catch (e) { print e; }`, "Undefined variable 'undefinedVariable'.\n", false},
+		{`try { [1][3]; } catch (e) { print e.message; }`, "List index 3 out of range for list of length 1.\n", false},
+		{"try {\n\n -\"a\"; } catch (e) { print e.line; }", "3\n", false},
+		{`try { nil(); } catch (e) { print e.message; }`, "Can only call functions and classes.\n", false},
+
+		// Finally always runs
+		{`try { print 1; } finally { print 2; }`, "1\n2\n", false},
+		{`try { throw 1; } catch (e) { print "catch"; } finally { print "finally"; }`, "catch\nfinally\n", false},
+		{`try { try { throw "x"; } finally { print "inner"; } } catch (e) { print e; }`, "inner\nx\n", false},
+		{`fun f() { try { return "value"; } finally { print "cleanup"; } } print f();`, "cleanup\nvalue\n", false},
+		{`fun f() { try { return 1; } finally { return 2; } } print f();`, "2\n", false},
+		{
+			`for (var i = 0; i < 3; i = i + 1) { try { if (i == 1) break; print i; } finally { print "finally"; } }`,
+			"0\nfinally\nfinally\n",
+			false,
+		},
+		{
+			`for (var i = 0; i < 2; i = i + 1) { try { continue; } finally { print i; } }`,
+			"0\n1\n",
+			false,
+		},
+		{`while (true) { try { throw "lost"; } finally { break; } } print "done";`, "done\n", false},
+
+		// Errors
+		{`throw "uncaught";`, "", true},
+		{`try { throw 1; } catch (e) { throw e; }`, "", true},
+		{`try { 1 / 0; } finally { print "still runs"; }`, "", true},
+		{`try { print 1; }`, "", true},
+		{`try { } catch { }`, "", true},
+		{`try { } catch (e) { } print e;`, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			output, didError := runProgram(tt.input)
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestRuntimeErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		message string
+		line    int
+		column  int
+		trace   []StackFrame
+	}{
+		{"top level", "print 1;\nprint -\"a\";", "Operand must be a number.", 2, 7, nil},
+		{"undefined variable", "print missing;", "Undefined variable 'missing'.", 1, 7, nil},
+		{"nested calls", "fun inner() {\n  return 1 / 0;\n}\nfun outer() {\n  return inner();\n}\nouter();",
+			"Division by zero.", 2, 12, []StackFrame{{"inner()", 5}, {"outer()", 7}}},
+		{"anonymous function", "var f = () => nil();\nf();",
+			"Can only call functions and classes.", 1, 19, []StackFrame{{"anonymous function", 2}}},
+		{"initializer", "class P {\n  init() { this.x = -nil; }\n}\nP();",
+			"Operand must be a number.", 2, 21, []StackFrame{{"P()", 4}}},
+		{"uncaught throw", "fun fail() { throw \"boom\"; }\nfail();",
+			"Uncaught exception: boom", 1, 14, []StackFrame{{"fail()", 2}}},
+		{"caught errors leave no trace", "fun f() { try { 1 / 0; } catch (e) {} return nil(); }\nf();",
+			"Can only call functions and classes.", 1, 50, []StackFrame{{"f()", 2}}},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tokens := NewScanner(tt.input, &bytes.Buffer{}).ScanTokens()
+			statements, err := NewParser(tokens, &bytes.Buffer{}).ParseStatements()
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+			interpreter := NewInterpreter(nil, nil, nil)
+			NewResolver(interpreter).Resolve(statements)
+
+			err = interpreter.InterpretStatements(statements)
+
+			runtimeError, ok := err.(*LoxRuntimeError)
+			if !ok {
+				t.Fatalf("Expected a *LoxRuntimeError, but got %T: %v", err, err)
+			}
+			if runtimeError.Message != tt.message {
+				t.Errorf("Expected message %q, but got %q", tt.message, runtimeError.Message)
+			}
+			if runtimeError.Token.Line != tt.line || runtimeError.Token.Column != tt.column {
+				t.Errorf("Expected position %d:%d, but got %d:%d", tt.line, tt.column, runtimeError.Token.Line, runtimeError.Token.Column)
+			}
+			if fmt.Sprint(runtimeError.Trace) != fmt.Sprint(tt.trace) {
+				t.Errorf("Expected trace %v, but got %v", tt.trace, runtimeError.Trace)
+			}
+		})
+	}
+}
+
+func TestSuggestions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"global variable", `var count = 1; try { print cuont; } catch (e) { print e.message; }`,
+			"Undefined variable 'cuont'. Did you mean 'count'?\n"},
+		{"enclosing local", `{ var total = 0; fun f() { try { totl = 1; } catch (e) { print e.message; } } f(); }`,
+			"Undefined variable 'totl'. Did you mean 'total'?\n"},
+		{"nothing close", `var count = 1; try { print zebra; } catch (e) { print e.message; }`,
+			"Undefined variable 'zebra'.\n"},
+		{"single letters", `var a = 1; try { print b; } catch (e) { print e.message; }`,
+			"Undefined variable 'b'.\n"},
+		{"instance field", `class P { init() { this.width = 1; } } try { print P().widht; } catch (e) { print e.message; }`,
+			"Undefined property 'widht'. Did you mean 'width'?\n"},
+		{"inherited method", `class A { area() {} } class B < A {} try { B().aera(); } catch (e) { print e.message; }`,
+			"Undefined property 'aera'. Did you mean 'area'?\n"},
+		{"super method", `class A { speak() {} } class B < A { f() { super.speek(); } } try { B().f(); } catch (e) { print e.message; }`,
+			"Undefined property 'speek'. Did you mean 'speak'?\n"},
+		{"error property", `try { 1 / 0; } catch (e) { try { print e.mesage; } catch (e2) { print e2.message; } }`,
+			"Undefined property 'mesage'. Did you mean 'message'?\n"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			output, _ := runProgram(tt.input)
+			if output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"count", "count", 0},
+		{"cuont", "count", 1},
+		{"cont", "count", 1},
+		{"counts", "count", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+		{"héllo", "hello", 1},
+	}
+
+	for _, tt := range tests {
+		if distance := editDistance(tt.a, tt.b); distance != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, expected %d", tt.a, tt.b, distance, tt.expected)
+		}
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		file     string
+		sources  []string
+		expected string
+	}{
+		{"scan error", "", []string{"var a = 1;\nvar b = @;"},
+			"error: Unexpected character.\n" +
+				" --> line 2, column 9\n" +
+				"  |\n" +
+				"2 | var b = @;\n" +
+				"  |         ^\n"},
+		{"parse error at end", "main.lox", []string{"print 1 +"},
+			"error: Expect expression.\n" +
+				" --> main.lox:1:10\n" +
+				"  |\n" +
+				"1 | print 1 +\n" +
+				"  |          ^\n"},
+		{"resolve error", "", []string{"{ var a = 1; var a = 2; }"},
+			"error: Variable with name 'a' already declared in this scope.\n" +
+				" --> line 1, column 18\n" +
+				"  |\n" +
+				"1 | { var a = 1; var a = 2; }\n" +
+				"  |                  ^\n"},
+		{"type error", "", []string{"var n: number = \"one\";"},
+			"type error: Expected number for variable 'n' but got string.\n" +
+				" --> line 1, column 17\n" +
+				"  |\n" +
+				"1 | var n: number = \"one\";\n" +
+				"  |                 ^~~~~\n"},
+		{"runtime error underlines the expression", "main.lox", []string{"fun half(x) {\n\treturn x / 0;\n}\n\n\n\n\n\n\nhalf(1);"},
+			"runtime error: Division by zero.\n" +
+				" --> main.lox:2:9\n" +
+				"  |\n" +
+				"2 | \treturn x / 0;\n" +
+				"  | \t       ^~~~~\n" +
+				"  = in half(), called at line 10\n"},
+		{"runtime error in an earlier entry", "", []string{"fun f(x) {\n  return -x;\n}", "f(\"a\");"},
+			"runtime error: Operand must be a number.\n" +
+				" --> line 2, column 10\n" +
+				"  |\n" +
+				"2 |   return -x;\n" +
+				"  |          ^~\n" +
+				"  = in f(), called at line 1\n"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var errBuf bytes.Buffer
+			interpreter := New(Options{Stdout: io.Discard, Stderr: &errBuf})
+			interpreter.file = tt.file
+			for _, source := range tt.sources {
+				_ = interpreter.Run(source)
+			}
+			if errBuf.String() != tt.expected {
+				t.Errorf("Expected:\n%s\nbut got:\n%s", tt.expected, errBuf.String())
+			}
+		})
+	}
+}
+
+func TestSpanOf(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 + 2 * 3", "1 + 2 * 3"},
+		{"-(a)", "-(a)"},
+		{"point.x = [1, 2][0]", "point.x = [1, 2][0]"},
+		{"f(a, {\"k\": 1})", "f(a, {\"k\": 1})"},
+		{"a ? b : c", "a ? b : c"},
+		{"(x) => x + 1", "(x) => x + 1"},
+		{"fun (x) { return x; }", "fun (x) { return x; }"},
+		{"  super.method  ", "super.method"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			tokens := NewScanner(tt.input, nil).ScanTokens()
+			expr, err := NewParser(tokens, nil).Parse()
+			if err != nil {
+				t.Fatalf("Unexpected parse error: %v", err)
+			}
+			span := SpanOf(expr)
+			if text := tt.input[span.Start:span.End]; text != tt.expected {
+				t.Errorf("Expected span %q, but got %q", tt.expected, text)
+			}
+		})
+	}
+}
+
+func TestModules(t *testing.T) {
+	t.Parallel()
+	files := map[string]string{
+		"lib/math.lox":    `print "loading math"; var pi = 3; fun square(x) { return x * x; } import "helpers.lox"; fun twice(x) { return helpers.double(x); }`,
+		"lib/helpers.lox": `fun double(x) { return x * 2; }`,
+		"lib/counter.lox": `var count = 0; fun increment() { count = count + 1; return count; }`,
+		"lib/broken.lox":  `var = 1;`,
+		"lib/my-lib.lox":  `var x = 1;`,
+		"lib/length.lox":  `var n = len("abc");`,
+		"lib/host.lox":    `var greeting = greet("module");`,
+		"cycle/a.lox":     `import "b.lox";`,
+		"cycle/b.lox":     `import "a.lox";`,
+		"self.lox":        `import "self.lox";`,
+	}
+
+	dir := t.TempDir()
+	for name, source := range files {
+		path := dir + "/" + name
+		if err := os.MkdirAll(path[:strings.LastIndex(path, "/")], 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Namespaces
+		{`import "lib/math.lox" as m; print m.square(4); print m.pi;`, "loading math\n16\n3\n", false},
+		{`import "lib/math.lox"; print math.twice(5);`, "loading math\n10\n", false},
+		{`import "lib/helpers.lox" as h; var double = h.double; print double(1);`, "2\n", false},
+		{`import "lib/helpers.lox" as h; print h;`, "<module helpers>\n", false},
+		{`fun f() { import "lib/helpers.lox" as h; return h.double(2); } print f();`, "4\n", false},
+
+		// Modules run once and keep their own globals
+		{`import "lib/math.lox" as a; import "lib/math.lox" as b; print a.pi + b.pi;`, "loading math\n6\n", false},
+		{`import "lib/counter.lox" as c; c.increment(); import "lib/counter.lox" as d; print d.increment(); print c.count;`, "2\n2\n", false},
+		{`var pi = "mine"; import "lib/math.lox" as m; print pi;`, "loading math\nmine\n", false},
+
+		// Built-ins are visible inside a module but are not its members
+		{`import "lib/length.lox" as l; print l.n;`, "3\n", false},
+		{`import "lib/helpers.lox" as h; print h.clock;`, "", true},
+
+		// "as" is only special after an import's path
+		{`var as = 1; fun f(as) { return as + 1; } print f(as);`, "2\n", false},
+		{`import "lib/helpers.lox" as as; print as.double(3);`, "6\n", false},
+
+		// Errors
+		{`import "lib/math.lox" as m; print m.missing;`, "", true},
+		{`import "lib/missing.lox";`, "", true},
+		{`import "lib/broken.lox";`, "", true},
+		{`import "lib/my-lib.lox";`, "", true},
+		{`import "lib/my-lib.lox" as lib; print lib.x;`, "1\n", false},
+		{`import "cycle/a.lox";`, "", true},
+		{`import "self.lox";`, "", true},
+		{`import lib;`, "", true},
+		{`import "lib/helpers.lox" as;`, "", true},
+		{`import "lib/helpers.lox" h;`, "", true},
+	}
+
+	for n, tt := range tests {
+		tt := tt
+		// Each program runs as a script in dir, so imports are resolved
+		// relative to it.
+		script := filepath.Join(dir, fmt.Sprintf("script%d.lox", n))
+		if err := os.WriteFile(script, []byte(tt.input), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			var outBuf bytes.Buffer
+			err := New(Options{Stdout: &outBuf, Stderr: io.Discard}).RunFile(script)
+			output, didError := outBuf.String(), err != nil
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+
+	// A broken module is reported against its own source, not as a runtime
+	// error at the import.
+	main := dir + "/main.lox"
+	if err := os.WriteFile(main, []byte("var x = 1;\nimport \"lib/broken.lox\";"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var errBuf bytes.Buffer
+	err := New(Options{Stdout: io.Discard, Stderr: &errBuf}).RunFile(main)
+	var moduleError *ModuleError
+	if !errors.As(err, &moduleError) || moduleError.Module != "broken.lox" {
+		t.Fatalf("Expected a *ModuleError for broken.lox, but got %T: %v", err, err)
+	}
+	if _, ok := moduleError.Err.(ParseErrors); !ok {
+		t.Errorf("Expected the module's ParseErrors, but got %T", moduleError.Err)
+	}
+	expected := "error: Expect variable name.\n" +
+		" --> broken.lox:1:5\n" +
+		"  |\n" +
+		"1 | var = 1;\n" +
+		"  |     ^\n" +
+		"  = in module 'broken.lox', imported at line 2\n"
+	if errBuf.String() != expected {
+		t.Errorf("Expected:\n%s\nbut got:\n%s", expected, errBuf.String())
+	}
+
+	// Natives the host defines are visible to the modules it imports.
+	if err := os.WriteFile(main, []byte(`import "lib/host.lox" as h; print h.greeting;`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var outBuf bytes.Buffer
+	host := New(Options{Stdout: &outBuf, Stderr: io.Discard})
+	host.DefineNative("greet", 1, func(arguments []interface{}) (interface{}, error) {
+		return "hello, " + arguments[0].(string), nil
+	})
+	if err := host.RunFile(main); err != nil {
+		t.Fatalf("Expected the module to call greet, but got: %v", err)
+	}
+	if outBuf.String() != "hello, module\n" {
+		t.Errorf("Expected output: %q, but got: %q", "hello, module\n", outBuf.String())
+	}
+}
+
+func TestTypeAnnotations(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input       string
+		expected    string
+		shouldError bool
+	}{
+		// Annotated code that checks
+		{`var x: number = 1; print x;`, "1\n", false},
+		{`var s: string; s = "later"; print s;`, "later\n", false},
+		{`var maybe: string | nil = nil; maybe = "now"; print maybe;`, "now\n", false},
+		{`fun add(a: number, b: number): number { return a + b; } print add(1, 2);`, "3\n", false},
+		{`fun greet(name: string): string { return "Hi " + name; } print greet("Ann");`, "Hi Ann\n", false},
+		{`fun nothing(): nil { return; } print nothing();`, "nil\n", false},
+		{`var anything: any = 1; anything = "two"; print anything;`, "two\n", false},
+		{`var xs: list = [1]; var m: map = {}; var f: function = (a: number) => a; print f(2);`, "2\n", false},
+		{`var flag: bool = 1 < 2 and true; print flag;`, "true\n", false},
+		{`var n: number = true ? 1 : 2; print n;`, "1\n", false},
+
+		// Type aliases and classes
+		{`type Id = number | string; var id: Id = "abc"; id = 7; print id;`, "7\n", false},
+		{`type Name = string; type Label = Name | nil; var l: Label = nil; print l;`, "nil\n", false},
+		{`fun make(): Point { return Point(); } class Point {} var p: Point = make(); print "ok";`, "ok\n", false},
+		{`class Animal {} class Dog < Animal {} var a: Animal = Dog(); print "ok";`, "ok\n", false},
+		{`class Counter { get(): Counter { return this; } } print "ok";`, "ok\n", false},
+
+		// 'type' is only special at the start of an alias
+		{`var type = 2; print type;`, "2\n", false},
+		{`fun f(type) { return type; } print f(1);`, "1\n", false},
+		{`var type = 1; type = type + 1; print type;`, "2\n", false},
+
+		// Arrow functions take the same annotations as 'fun'
+		{`var f = (x: number): number => x * 2; print f(3);`, "6\n", false},
+		{`var f = (x): string | nil => nil; print f(1);`, "nil\n", false},
+		{`var x = 1; var y = 2; print true ? (x) : y;`, "1\n", false},
+
+		// Unannotated code keeps working unchanged
+		{`var x = 1; x = "now a string"; print x;`, "now a string\n", false},
+		{`fun id(v) { return v; } var n: number = id("not checked"); print n;`, "not checked\n", false},
+		{`fun f(a) { return a; } print f(1) + f(2);`, "3\n", false},
+
+		// Mismatches are reported before anything runs
+		{`print "before"; var x: number = "one";`, "", true},
+		{`var x: number = 1; x = "two";`, "", true},
+		{`var s: string = nil;`, "", true},
+		{`fun f(a: number) {} f("one");`, "", true},
+		{`fun f(): string { return 1; }`, "", true},
+		{`fun f(): number { return; }`, "", true},
+		{`var f = fun (): bool { return "no"; };`, "", true},
+		{`var f = (x: number): string => x;`, "", true},
+		{`class Animal {} class Rock {} var a: Animal = Rock();`, "", true},
+		{`class Animal {} class Dog < Animal {} var d: Dog = Animal();`, "", true},
+		{`type Id = number; var id: Id = "abc";`, "", true},
+		{`var x: Unknown = 1;`, "", true},
+		{`type Loop = Loop; var x: Loop = 1;`, "", true},
+		{`var n: number = 1 + "a" == "b";`, "", true},
+
+		// Syntax errors in annotations
+		{`var x: = 1;`, "", true},
+		{`fun f(a:) {}`, "", true},
+		{`type Id number;`, "", true},
+		{`type Id = ;`, "", true},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			output, didError := runProgram(tt.input)
+
+			if didError != tt.shouldError {
+				t.Errorf("Expected error: %v, but got: %v", tt.shouldError, didError)
+				return
+			}
+
+			if !tt.shouldError && output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+		})
+	}
+}
+
+func TestREPLSession(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		lines    []string
+		expected string
+		errors   int
+	}{
+		{"globals persist", []string{"var a = 1;", "print a;"}, "1\n", 0},
+		{"functions and classes persist", []string{
+			"fun square(x) { return x * x; }",
+			"class Box { init(v) { this.v = v; } }",
+			"print square(Box(3).v);",
+		}, "9\n", 0},
+		{"closures keep resolved locals", []string{
+			"fun counter() { var n = 0; return () => { n = n + 1; return n; }; }",
+			"var c = counter();",
+			"c();",
+			"print c();",
+		}, "1\n2\n", 0},
+		{"bare expressions are echoed", []string{"1 + 2", "var s = \"hi\";", "s", "nil", "[1, 2];"}, "3\nhi\n[1, 2]\n", 0},
+		{"errors keep earlier definitions", []string{"var a = 1;", "print missing;", "var b = a +;", "print a;"}, "1\n", 2},
+		{"runtime error mid-line", []string{"var a = 1;", "a = 2; print 1 / 0; a = 3;", "print a;"}, "2\n2\n", 1},
+		{"type annotations persist", []string{"var n: number = 1;", "n = \"one\";", "print n;"}, "1\n", 1},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var outBuf, errBuf bytes.Buffer
+			session := newSession(bufio.NewReader(strings.NewReader("")), &outBuf, &errBuf)
+			for _, line := range tt.lines {
+				session.runLine(line)
+			}
+
+			if output := outBuf.String(); output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+			if errors := strings.Count(strings.ToLower(errBuf.String()), "error"); errors != tt.errors {
+				t.Errorf("Expected %d errors, but got %d: %q", tt.errors, errors, errBuf.String())
+			}
+		})
+	}
+}
+
+func TestREPLInput(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		complete bool
+	}{
+		{"simple statement", "print 1;", true},
+		{"bare expression", "1 + 2", true},
+		{"open brace", "fun f() {", false},
+		{"closed brace", "fun f() {\n  return 1;\n}", true},
+		{"open paren", "print (1 +", false},
+		{"open bracket", "var xs = [1,", false},
+		{"nested", "var m = {\"a\": [1, (2", false},
+		{"unterminated string", "var s = \"abc", false},
+		{"terminated string", "var s = \"abc\ndef\";", true},
+		{"bracket inside string", "print \"{\";", true},
+		{"unterminated block comment", "/* comment", false},
+		{"bracket inside comment", "print 1; // {", true},
+		{"stray closing brace", "}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if complete := inputComplete(tt.input); complete != tt.complete {
+				t.Errorf("inputComplete(%q) = %v, want %v", tt.input, complete, tt.complete)
+			}
+		})
+	}
+}
+
+func TestREPL(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.lox")
+	if err := os.WriteFile(script, []byte("var loaded = \"yes\";\nfun twice(x) { return x * 2; }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		hasError bool
+	}{
+		{"multi-line function", "fun add(a, b) {\n  return a + b;\n}\nadd(1, 2)\n", "3\n", false},
+		{"multi-line string", "print \"one\ntwo\";\n", "one\ntwo\n", false},
+		{"multi-line list", "[1,\n2,\n3]\n", "[1, 2, 3]\n", false},
+		{"load", ":load " + script + "\nprint loaded;\ntwice(4)\n", "yes\n8\n", false},
+		{"load missing file", ":load " + filepath.Join(dir, "missing.lox") + "\n", "", true},
+		{"reset", "var a = 1;\n:reset\nprint a;\n", "", true},
+		{"quit", "print 1;\n:quit\nprint 2;\n", "1\n", false},
+		{"unknown command", ":frobnicate\nprint 1;\n", "1\n", true},
+		{"incomplete entry at end of input", "fun f() {\n", "", true},
+		{"tokens", ":tokens print x;\n", "LINE  START  COLUMN  TYPE        LEXEME  LITERAL\n" +
+			"1     0      1       PRINT       print   \n" +
+			"1     6      7       IDENTIFIER  x       \n" +
+			"1     7      8       SEMICOLON   ;       \n" +
+			"1     8      9       EOF                 \n", false},
+		{"tokens over several lines", ":tokens (1,\n2)\n", "LINE  START  COLUMN  TYPE         LEXEME  LITERAL\n" +
+			"1     0      1       LEFT_PAREN   (       \n" +
+			"1     1      2       NUMBER       1       1\n" +
+			"1     2      3       COMMA        ,       \n" +
+			"2     4      1       NUMBER       2       2\n" +
+			"2     5      2       RIGHT_PAREN  )       \n" +
+			"2     6      3       EOF                  \n", false},
+		{"ast", ":ast var a = -1 + 2 * 3\n", "(var a (+ (- 1) (* 2 3)))\n", false},
+		{"ast resolves locals", ":ast fun f(a) {\n  var b = a;\n  return fun () { return a + b; };\n}\n",
+			"(fun f (a)\n  (var b a@0)\n  (return (fun ()\n    (return (+ a@1 b@1)))))\n", false},
+		{"ast does not run code", ":ast var a = 1;\nprint a;\n", "(var a 1)\n", true},
+		{"ast parse error", ":ast var = 1;\n", "", true},
+		{"env", "var n = 1;\nfun f() {}\nvar xs = [1];\n:env\n", "NAME   KIND      VALUE\n" +
+			"f      function  <fn f>\n" +
+			"n      value     1\n" +
+			"xs     value     [1]\n" +
+			"clock  native    <native fn>\n" +
+			"input  native    <native fn>\n" +
+			"len    native    <native fn>\n" +
+			"num    native    <native fn>\n" +
+			"str    native    <native fn>\n" +
+			"type   native    <native fn>\n", false},
+		{"time", ":time 1 + 2\n", "3\n", false},
+		{"input reads the next line", "var x = input();\nhello\nprint x;\n", "hello\n", false},
+		{"input with a prompt", "print input(\"Name? \") + \"!\";\nAda\n", "Name? Ada!\n", false},
+		{"input at end of input", "print input();\n", "nil\n", false},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var outBuf, errBuf bytes.Buffer
+			repl := newREPL(strings.NewReader(tt.input), &outBuf, &errBuf)
+			repl.editor.out = io.Discard // leave the prompts out
+			repl.run()
+
+			output := outBuf.String()
+			if strings.HasPrefix(tt.input, ":time") {
+				// The elapsed time varies, so only check it is reported.
+				if elapsed := strings.Index(output, "Elapsed: "); elapsed >= 0 {
+					output = output[:elapsed]
+				} else {
+					t.Errorf("Expected elapsed time in output: %q", output)
+				}
+			}
+			if output != tt.expected {
+				t.Errorf("Expected output: %q, but got: %q", tt.expected, output)
+			}
+			if hasError := errBuf.Len() > 0; hasError != tt.hasError {
+				t.Errorf("Expected error: %v, but got: %q", tt.hasError, errBuf.String())
+			}
+		})
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package lox
 
 import "fmt"
 
@@ -34,6 +34,8 @@ const (
 	TokenGreaterEqual // '>='
 	TokenLess         // '<'
 	TokenLessEqual    // '<='
+	TokenPipeGreater  // '|>'
+	TokenArrow        // '=>'
 
 	// Literals
 	TokenIdentifier // Identifiers (variable/function names)
@@ -59,20 +61,87 @@ const (
 	TokenWhile    // "while"
 	TokenBreak    // "break"
 	TokenContinue // "continue"
-
-	// Special type declaration token
-	TokenTypeType // Used for type declarations
+	TokenThrow    // "throw"
+	TokenTry      // "try"
+	TokenCatch    // "catch"
+	TokenFinally  // "finally"
+	TokenImport   // "import"
 
 	// End-of-file token
 	TokenEof
 )
 
+var tokenTypeNames = [...]string{
+	TokenLeftParen:    "LEFT_PAREN",
+	TokenRightParen:   "RIGHT_PAREN",
+	TokenLeftBrace:    "LEFT_BRACE",
+	TokenRightBrace:   "RIGHT_BRACE",
+	TokenLeftBracket:  "LEFT_BRACKET",
+	TokenRightBracket: "RIGHT_BRACKET",
+	TokenComma:        "COMMA",
+	TokenDot:          "DOT",
+	TokenMinus:        "MINUS",
+	TokenPlus:         "PLUS",
+	TokenSemicolon:    "SEMICOLON",
+	TokenSlash:        "SLASH",
+	TokenStar:         "STAR",
+	TokenColon:        "COLON",
+	TokenQuestionMark: "QUESTION_MARK",
+	TokenPipe:         "PIPE",
+	TokenBang:         "BANG",
+	TokenBangEqual:    "BANG_EQUAL",
+	TokenEqual:        "EQUAL",
+	TokenEqualEqual:   "EQUAL_EQUAL",
+	TokenGreater:      "GREATER",
+	TokenGreaterEqual: "GREATER_EQUAL",
+	TokenLess:         "LESS",
+	TokenLessEqual:    "LESS_EQUAL",
+	TokenPipeGreater:  "PIPE_GREATER",
+	TokenArrow:        "ARROW",
+	TokenIdentifier:   "IDENTIFIER",
+	TokenString:       "STRING",
+	TokenNumber:       "NUMBER",
+	TokenAnd:          "AND",
+	TokenClass:        "CLASS",
+	TokenElse:         "ELSE",
+	TokenFalse:        "FALSE",
+	TokenFun:          "FUN",
+	TokenFor:          "FOR",
+	TokenIf:           "IF",
+	TokenNil:          "NIL",
+	TokenOr:           "OR",
+	TokenPrint:        "PRINT",
+	TokenReturn:       "RETURN",
+	TokenSuper:        "SUPER",
+	TokenThis:         "THIS",
+	TokenTrue:         "TRUE",
+	TokenVar:          "VAR",
+	TokenWhile:        "WHILE",
+	TokenBreak:        "BREAK",
+	TokenContinue:     "CONTINUE",
+	TokenThrow:        "THROW",
+	TokenTry:          "TRY",
+	TokenCatch:        "CATCH",
+	TokenFinally:      "FINALLY",
+	TokenImport:       "IMPORT",
+	TokenEof:          "EOF",
+}
+
+// String returns the name of the token type, as shown by the REPL's :tokens.
+func (t TokenType) String() string {
+	if int(t) < len(tokenTypeNames) {
+		return tokenTypeNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", uint8(t))
+}
+
 // Token represents a single unit of lexical information in the program.
 type Token struct {
 	TokenType TokenType   // The type of the token.
 	Lexeme    string      // The textual representation of the token.
 	Literal   interface{} // The literal value (if applicable, e.g., for strings or numbers).
 	Line      int         // Line number where the token appears.
+	Column    int         // Column of the token's first character, counting characters from 1.
 	Start     int         // Index from the start of the program.
 }
 
@@ -0,0 +1,557 @@
+package lox
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// staticType is the TypeChecker's view of a value: the sorted set of type
+// names it may have. A nil staticType is `any`, which is compatible with
+// every other type, so unannotated code is never rejected.
+type staticType []string
+
+var typeAny staticType
+
+// builtinTypes are the type names that are always in scope.
+var builtinTypes = map[string]bool{
+	"any":      true,
+	"bool":     true,
+	"error":    true,
+	"function": true,
+	"list":     true,
+	"map":      true,
+	"module":   true,
+	"nil":      true,
+	"number":   true,
+	"string":   true,
+}
+
+func typeOf(names ...string) staticType {
+	set := make(map[string]bool)
+	for _, name := range names {
+		if name == "any" {
+			return typeAny
+		}
+		set[name] = true
+	}
+	result := make(staticType, 0, len(set))
+	for name := range set {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+func unionOf(a, b staticType) staticType {
+	if a == nil || b == nil {
+		return typeAny
+	}
+	return typeOf(append(append([]string{}, a...), b...)...)
+}
+
+func (t staticType) String() string {
+	if t == nil {
+		return "any"
+	}
+	return strings.Join(t, " | ")
+}
+
+// TypeError is a mismatch between a value and a type annotation.
+type TypeError struct {
+	Token   Token
+	Span    Span // the offending expression, if there is one
+	Message string
+}
+
+func (e TypeError) Error() string {
+	return fmt.Sprintf("[line %d] Type error at '%s': %s", e.Token.Line, e.Token.Lexeme, e.Message)
+}
+
+// TypeErrors is every error found by a type-checking pass.
+type TypeErrors []TypeError
+
+func (e TypeErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, typeError := range e {
+		messages[i] = typeError.Error()
+	}
+	return strings.Join(messages, "\n")
+}
+
+// typeSymbol is what the TypeChecker knows about a variable.
+type typeSymbol struct {
+	declared  staticType
+	signature *signature // set for named functions
+	class     string     // set for classes
+}
+
+// signature is the annotated shape of a function.
+type signature struct {
+	params []staticType
+	result staticType
+}
+
+// TypeChecker is a static pass that runs after the Resolver and checks values
+// against optional type annotations. Anything it cannot infer is `any`.
+type TypeChecker struct {
+	scopes       []map[string]*typeSymbol
+	aliases      map[string]*TypeAnnotation
+	classes      map[string]string // class name to superclass name
+	returnTypes  []staticType
+	currentClass string
+	errors       []TypeError
+}
+
+// NewTypeChecker creates a TypeChecker whose global scope persists across calls to Check.
+func NewTypeChecker() *TypeChecker {
+	return &TypeChecker{
+		scopes:  []map[string]*typeSymbol{{}},
+		aliases: make(map[string]*TypeAnnotation),
+		classes: make(map[string]string),
+	}
+}
+
+// Check type-checks statements and returns the mismatches found.
+func (c *TypeChecker) Check(statements []Stmt) []TypeError {
+	c.errors = nil
+	c.checkStatements(statements)
+	return c.errors
+}
+
+func (c *TypeChecker) checkStatements(statements []Stmt) {
+	// Classes and aliases may be named in annotations before their declaration.
+	for _, stmt := range statements {
+		switch declaration := stmt.(type) {
+		case *ClassStmt:
+			c.declareClass(declaration)
+		case *TypeAliasStmt:
+			c.aliases[declaration.Name.Lexeme] = declaration.Type
+		}
+	}
+	for _, stmt := range statements {
+		stmt.Accept(c)
+	}
+}
+
+func (c *TypeChecker) check(expr Expr) staticType {
+	if t, ok := expr.Accept(c).(staticType); ok {
+		return t
+	}
+	return typeAny
+}
+
+func (c *TypeChecker) error(token Token, message string) {
+	c.errors = append(c.errors, TypeError{Token: token, Message: message})
+}
+
+func (c *TypeChecker) beginScope() {
+	c.scopes = append(c.scopes, map[string]*typeSymbol{})
+}
+
+func (c *TypeChecker) endScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+func (c *TypeChecker) define(name string, symbol *typeSymbol) {
+	c.scopes[len(c.scopes)-1][name] = symbol
+}
+
+func (c *TypeChecker) lookup(name string) *typeSymbol {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if symbol, found := c.scopes[i][name]; found {
+			return symbol
+		}
+	}
+	return nil
+}
+
+func (c *TypeChecker) declareClass(stmt *ClassStmt) {
+	superclass := ""
+	if stmt.Superclass != nil {
+		superclass = stmt.Superclass.Name.Lexeme
+	}
+	c.classes[stmt.Name.Lexeme] = superclass
+}
+
+// resolve turns an annotation into a staticType, expanding aliases.
+func (c *TypeChecker) resolve(annotation *TypeAnnotation) staticType {
+	if annotation == nil {
+		return typeAny
+	}
+	return c.resolveNames(annotation, map[string]bool{})
+}
+
+func (c *TypeChecker) resolveNames(annotation *TypeAnnotation, expanding map[string]bool) staticType {
+	var names []string
+	for _, name := range annotation.Names {
+		switch {
+		case builtinTypes[name.Lexeme]:
+			if name.Lexeme == "any" {
+				return typeAny
+			}
+			names = append(names, name.Lexeme)
+		case c.isClass(name.Lexeme):
+			names = append(names, name.Lexeme)
+		case c.aliases[name.Lexeme] != nil:
+			if expanding[name.Lexeme] {
+				c.error(name, fmt.Sprintf("Type alias '%s' refers to itself.", name.Lexeme))
+				return typeAny
+			}
+			expanding[name.Lexeme] = true
+			aliased := c.resolveNames(c.aliases[name.Lexeme], expanding)
+			delete(expanding, name.Lexeme)
+			if aliased == nil {
+				return typeAny
+			}
+			names = append(names, aliased...)
+		default:
+			c.error(name, fmt.Sprintf("Unknown type '%s'.", name.Lexeme))
+			return typeAny
+		}
+	}
+	return typeOf(names...)
+}
+
+func (c *TypeChecker) isClass(name string) bool {
+	_, found := c.classes[name]
+	return found
+}
+
+// assignable reports whether every value of type from is also of type to.
+func (c *TypeChecker) assignable(from, to staticType) bool {
+	if from == nil || to == nil {
+		return true
+	}
+	for _, name := range from {
+		if !c.acceptsName(to, name) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *TypeChecker) acceptsName(to staticType, name string) bool {
+	for _, target := range to {
+		for current := name; current != ""; current = c.classes[current] {
+			if current == target {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expect reports an error at token unless value, the type of expr, fits
+// expected. context names what is being checked, e.g. "variable 'x'". expr
+// may be nil.
+func (c *TypeChecker) expect(token Token, expr Expr, value staticType, expected staticType, context string) {
+	if !c.assignable(value, expected) {
+		message := fmt.Sprintf("Expected %s for %s but got %s.", expected, context, value)
+		c.errors = append(c.errors, TypeError{Token: token, Span: SpanOf(expr), Message: message})
+	}
+}
+
+func (c *TypeChecker) signatureOf(params []*TypeAnnotation, returnType *TypeAnnotation) *signature {
+	sig := &signature{result: c.resolve(returnType)}
+	for _, param := range params {
+		sig.params = append(sig.params, c.resolve(param))
+	}
+	return sig
+}
+
+func (c *TypeChecker) checkFunction(params []Token, sig *signature, body []Stmt) {
+	c.beginScope()
+	for i, param := range params {
+		c.define(param.Lexeme, &typeSymbol{declared: sig.params[i]})
+	}
+	c.returnTypes = append(c.returnTypes, sig.result)
+	c.checkStatements(body)
+	c.returnTypes = c.returnTypes[:len(c.returnTypes)-1]
+	c.endScope()
+}
+
+// Statement visitors
+func (c *TypeChecker) VisitExpressionStmt(stmt *ExpressionStmt) interface{} {
+	c.check(stmt.Expression)
+	return nil
+}
+
+func (c *TypeChecker) VisitPrintStmt(stmt *PrintStmt) interface{} {
+	c.check(stmt.Expression)
+	return nil
+}
+
+func (c *TypeChecker) VisitVarStmt(stmt *VarStmt) interface{} {
+	declared := c.resolve(stmt.Type)
+	if stmt.Initializer != nil {
+		value := c.check(stmt.Initializer)
+		if stmt.Type != nil {
+			c.expect(stmt.Name, stmt.Initializer, value, declared, fmt.Sprintf("variable '%s'", stmt.Name.Lexeme))
+		}
+	}
+	c.define(stmt.Name.Lexeme, &typeSymbol{declared: declared})
+	return nil
+}
+
+func (c *TypeChecker) VisitBlockStmt(stmt *BlockStmt) interface{} {
+	c.beginScope()
+	c.checkStatements(stmt.Statements)
+	c.endScope()
+	return nil
+}
+
+func (c *TypeChecker) VisitIfStmt(stmt *IfStmt) interface{} {
+	c.check(stmt.Condition)
+	stmt.ThenBranch.Accept(c)
+	if stmt.ElseBranch != nil {
+		stmt.ElseBranch.Accept(c)
+	}
+	return nil
+}
+
+func (c *TypeChecker) VisitWhileStmt(stmt *WhileStmt) interface{} {
+	c.check(stmt.Condition)
+	stmt.Body.Accept(c)
+	if stmt.Increment != nil {
+		c.check(stmt.Increment)
+	}
+	return nil
+}
+
+func (c *TypeChecker) VisitBreakStmt(stmt *BreakStmt) interface{} {
+	return nil
+}
+
+func (c *TypeChecker) VisitContinueStmt(stmt *ContinueStmt) interface{} {
+	return nil
+}
+
+func (c *TypeChecker) VisitFunStmt(stmt *FunStmt) interface{} {
+	sig := c.signatureOf(stmt.ParamTypes, stmt.ReturnType)
+	c.define(stmt.Name.Lexeme, &typeSymbol{declared: typeOf("function"), signature: sig})
+	c.checkFunction(stmt.Params, sig, stmt.Body)
+	return nil
+}
+
+func (c *TypeChecker) VisitReturnStmt(stmt *ReturnStmt) interface{} {
+	value := typeOf("nil")
+	if stmt.Value != nil {
+		value = c.check(stmt.Value)
+	}
+	if len(c.returnTypes) > 0 {
+		expected := c.returnTypes[len(c.returnTypes)-1]
+		c.expect(stmt.Keyword, stmt.Value, value, expected, "the return value")
+	}
+	return nil
+}
+
+func (c *TypeChecker) VisitClassStmt(stmt *ClassStmt) interface{} {
+	c.declareClass(stmt)
+	if stmt.Superclass != nil {
+		c.check(stmt.Superclass)
+	}
+	c.define(stmt.Name.Lexeme, &typeSymbol{class: stmt.Name.Lexeme})
+
+	enclosingClass := c.currentClass
+	c.currentClass = stmt.Name.Lexeme
+	for _, method := range stmt.Methods {
+		sig := c.signatureOf(method.ParamTypes, method.ReturnType)
+		if method.Name.Lexeme == "init" {
+			// Initializers always produce the instance, whatever they return.
+			sig.result = typeAny
+		}
+		c.checkFunction(method.Params, sig, method.Body)
+	}
+	c.currentClass = enclosingClass
+	return nil
+}
+
+func (c *TypeChecker) VisitThrowStmt(stmt *ThrowStmt) interface{} {
+	c.check(stmt.Value)
+	return nil
+}
+
+func (c *TypeChecker) VisitTryStmt(stmt *TryStmt) interface{} {
+	c.beginScope()
+	c.checkStatements(stmt.TryBlock)
+	c.endScope()
+	if stmt.CatchName != nil {
+		c.beginScope()
+		c.define(stmt.CatchName.Lexeme, &typeSymbol{})
+		c.checkStatements(stmt.CatchBlock)
+		c.endScope()
+	}
+	if stmt.FinallyBlock != nil {
+		c.beginScope()
+		c.checkStatements(stmt.FinallyBlock)
+		c.endScope()
+	}
+	return nil
+}
+
+func (c *TypeChecker) VisitImportStmt(stmt *ImportStmt) interface{} {
+	c.define(stmt.Name.Lexeme, &typeSymbol{declared: typeOf("module")})
+	return nil
+}
+
+func (c *TypeChecker) VisitTypeAliasStmt(stmt *TypeAliasStmt) interface{} {
+	c.aliases[stmt.Name.Lexeme] = stmt.Type
+	c.resolve(stmt.Type)
+	return nil
+}
+
+// Expression visitors
+func (c *TypeChecker) VisitBinaryExpr(expr *Binary) interface{} {
+	left := c.check(expr.Left)
+	right := c.check(expr.Right)
+
+	switch expr.Operator.TokenType {
+	case TokenPlus:
+		if left == nil || right == nil {
+			return typeAny
+		}
+		number, str := typeOf("number"), typeOf("string")
+		if c.assignable(left, number) && c.assignable(right, number) {
+			return number
+		}
+		if c.assignable(left, str) && c.assignable(right, str) {
+			return str
+		}
+		return typeOf("number", "string")
+	case TokenMinus, TokenStar, TokenSlash:
+		return typeOf("number")
+	}
+	return typeOf("bool")
+}
+
+func (c *TypeChecker) VisitGroupingExpr(expr *Grouping) interface{} {
+	return c.check(expr.Expression)
+}
+
+func (c *TypeChecker) VisitLiteralExpr(expr *Literal) interface{} {
+	switch expr.Value.(type) {
+	case nil:
+		return typeOf("nil")
+	case bool:
+		return typeOf("bool")
+	case float64:
+		return typeOf("number")
+	case string:
+		return typeOf("string")
+	}
+	return typeAny
+}
+
+func (c *TypeChecker) VisitUnaryExpr(expr *Unary) interface{} {
+	c.check(expr.Right)
+	if expr.Operator.TokenType == TokenMinus {
+		return typeOf("number")
+	}
+	return typeOf("bool")
+}
+
+func (c *TypeChecker) VisitVariableExpr(expr *Variable) interface{} {
+	if symbol := c.lookup(expr.Name.Lexeme); symbol != nil {
+		return symbol.declared
+	}
+	return typeAny
+}
+
+func (c *TypeChecker) VisitAssignExpr(expr *Assign) interface{} {
+	value := c.check(expr.Value)
+	if symbol := c.lookup(expr.Name.Lexeme); symbol != nil {
+		c.expect(expr.Name, expr.Value, value, symbol.declared, fmt.Sprintf("variable '%s'", expr.Name.Lexeme))
+	}
+	return value
+}
+
+func (c *TypeChecker) VisitCallExpr(expr *Call) interface{} {
+	c.check(expr.Callee)
+	arguments := make([]staticType, len(expr.Arguments))
+	for i, argument := range expr.Arguments {
+		arguments[i] = c.check(argument)
+	}
+
+	variable, ok := expr.Callee.(*Variable)
+	if !ok {
+		return typeAny
+	}
+	symbol := c.lookup(variable.Name.Lexeme)
+	switch {
+	case symbol == nil:
+		return typeAny
+	case symbol.class != "":
+		return typeOf(symbol.class)
+	case symbol.signature != nil:
+		for i, param := range symbol.signature.params {
+			if i < len(arguments) {
+				c.expect(expr.Paren, expr.Arguments[i], arguments[i], param, fmt.Sprintf("argument %d of '%s'", i+1, variable.Name.Lexeme))
+			}
+		}
+		return symbol.signature.result
+	}
+	return typeAny
+}
+
+func (c *TypeChecker) VisitGetExpr(expr *GetExpr) interface{} {
+	c.check(expr.Object)
+	return typeAny
+}
+
+func (c *TypeChecker) VisitSetExpr(expr *SetExpr) interface{} {
+	c.check(expr.Object)
+	return c.check(expr.Value)
+}
+
+func (c *TypeChecker) VisitThisExpr(expr *ThisExpr) interface{} {
+	if c.currentClass == "" {
+		return typeAny
+	}
+	return typeOf(c.currentClass)
+}
+
+func (c *TypeChecker) VisitSuperExpr(expr *SuperExpr) interface{} {
+	return typeAny
+}
+
+func (c *TypeChecker) VisitLogicalExpr(expr *Logical) interface{} {
+	return unionOf(c.check(expr.Left), c.check(expr.Right))
+}
+
+func (c *TypeChecker) VisitListExpr(expr *ListExpr) interface{} {
+	for _, element := range expr.Elements {
+		c.check(element)
+	}
+	return typeOf("list")
+}
+
+func (c *TypeChecker) VisitIndexExpr(expr *IndexExpr) interface{} {
+	c.check(expr.Object)
+	c.check(expr.Index)
+	return typeAny
+}
+
+func (c *TypeChecker) VisitIndexSetExpr(expr *IndexSetExpr) interface{} {
+	c.check(expr.Object)
+	c.check(expr.Index)
+	return c.check(expr.Value)
+}
+
+func (c *TypeChecker) VisitMapExpr(expr *MapExpr) interface{} {
+	for i := range expr.Keys {
+		c.check(expr.Keys[i])
+		c.check(expr.Values[i])
+	}
+	return typeOf("map")
+}
+
+func (c *TypeChecker) VisitConditionalExpr(expr *Conditional) interface{} {
+	c.check(expr.Condition)
+	return unionOf(c.check(expr.ThenBranch), c.check(expr.ElseBranch))
+}
+
+func (c *TypeChecker) VisitLambdaExpr(expr *Lambda) interface{} {
+	c.checkFunction(expr.Params, c.signatureOf(expr.ParamTypes, expr.ReturnType), expr.Body)
+	return typeOf("function")
+}